@@ -3,11 +3,16 @@ package args
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/elsni/lagerator/config"
 	"github.com/elsni/lagerator/data"
 	"github.com/elsni/lagerator/logic"
 	"github.com/elsni/lagerator/terminal"
+	"github.com/elsni/lagerator/tui"
 )
 
 const appName = "Lagerator"
@@ -17,6 +22,126 @@ var appVersion = "1.0.12"
 var buildCommit = "unknown"
 var buildDate = "unknown"
 
+// dblessCommands run without loading the database first.
+var dblessCommands = map[string]bool{
+	"version":   true,
+	"--version": true,
+	"-v":        true,
+	"repair":    true,
+	"profile":   true,
+}
+
+// takeFlag removes "--name value" or "--name=value" from args and returns the value.
+// found is true even when the value is missing.
+func takeFlag(args *[]string, name string) (value string, found bool) {
+	for i, arg := range *args {
+		if arg == name {
+			if i+1 < len(*args) {
+				value = (*args)[i+1]
+				*args = append((*args)[:i:i], (*args)[i+2:]...)
+			} else {
+				*args = (*args)[:i]
+			}
+			return value, true
+		}
+		if strings.HasPrefix(arg, name+"=") {
+			*args = append((*args)[:i:i], (*args)[i+1:]...)
+			return arg[len(name)+1:], true
+		}
+	}
+	return "", false
+}
+
+// takeSwitch removes the boolean option name from args and reports whether it was given.
+func takeSwitch(args *[]string, name string) bool {
+	for i, arg := range *args {
+		if arg == name {
+			*args = append((*args)[:i:i], (*args)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// deleteOptions removes --cascade and --reparent from args.
+func deleteOptions(args *[]string) (logic.DeleteOptions, bool) {
+	opts := logic.DeleteOptions{Cascade: takeSwitch(args, "--cascade")}
+	reparent, found := takeFlag(args, "--reparent")
+	if found && reparent == "" {
+		fmt.Println("--reparent needs the name or id of the new parent")
+		return opts, false
+	}
+	if opts.Cascade && found {
+		fmt.Println("Use either --cascade or --reparent")
+		return opts, false
+	}
+	opts.Reparent = reparent
+	return opts, true
+}
+
+// globalFlags are the options that may precede the operation.
+var globalFlags = map[string]bool{"--db": true, "--profile": true, "--output": true, "--view": true}
+
+// globalPrefix returns the number of leading args that are global options
+// and their values. Anything after the operation belongs to the operation.
+func globalPrefix(args []string) int {
+	i := 0
+	for i < len(args) {
+		name, _, inline := strings.Cut(args[i], "=")
+		if !globalFlags[name] {
+			break
+		}
+		i++
+		if !inline && i < len(args) {
+			i++
+		}
+	}
+	return i
+}
+
+// applyGlobalFlags removes the global options in front of the operation from
+// args and configures the database path.
+func applyGlobalFlags(args *[]string) bool {
+	n := globalPrefix(*args)
+	global := slices.Clone((*args)[:n])
+	*args = (*args)[n:]
+	dbflag, dbfound := takeFlag(&global, "--db")
+	if dbfound && dbflag == "" {
+		fmt.Println("--db needs a file name")
+		return false
+	}
+	profile, profilefound := takeFlag(&global, "--profile")
+	if profilefound && profile == "" {
+		fmt.Println("--profile needs a profile name")
+		return false
+	}
+	if output, found := takeFlag(&global, "--output"); found {
+		format, err := data.ParseOutputFormat(output)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return false
+		}
+		data.Output = format
+	}
+	viewflag, viewfound := takeFlag(&global, "--view")
+	if viewfound && viewflag == "" {
+		fmt.Println("--view needs a view name")
+		return false
+	}
+	view = viewflag
+	if len(global) > 0 {
+		fmt.Printf("%s was given more than once\n", global[0])
+		return false
+	}
+	path, err := config.ResolveDbPath(dbflag, profile)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return false
+	}
+	data.SetPath(path)
+	return true
+}
+
 // requireArgs validates the minimum argument count.
 func requireArgs(should int, args []string) bool {
 	if len(args) < should {
@@ -45,14 +170,47 @@ func parseID(arg string, errMsg string) (uint32, bool) {
 	return id, true
 }
 
+// parseAge parses a duration like "90d", "2w" or "36h".
+func parseAge(arg string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, found := strings.CutSuffix(arg, suffix); found {
+			count, err := strconv.ParseUint(n, 10, 32)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration \"%s\"", arg)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(arg)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration \"%s\"", arg)
+	}
+	return d, nil
+}
+
 // versionString returns the formatted version string.
 func versionString() string {
 	return fmt.Sprintf("%s %s (%s, %s) by %s", appName, appVersion, buildCommit, buildDate, appAuthor)
 }
 
+// ExitCode is the exit status requested by the processed command.
+var ExitCode int
+
+// view is the saved view set with --view, it limits the item lists and reports.
+var view string
+
 // ProcessArgs routes CLI arguments to command handlers.
 func ProcessArgs() {
 	args := os.Args[1:]
+	if !applyGlobalFlags(&args) {
+		return
+	}
+	if data.Output == data.OutputText {
+		// blank lines around the output, machine-readable output is left as is
+		fmt.Println()
+		defer fmt.Println()
+	}
 	if len(args) < 1 {
 		PrintUsage()
 		return
@@ -64,6 +222,117 @@ func ProcessArgs() {
 		"version":   func(_ []string) { fmt.Println(versionString()) },
 		"--version": func(_ []string) { fmt.Println(versionString()) },
 		"-v":        func(_ []string) { fmt.Println(versionString()) },
+		"repair":    func(_ []string) { logic.Repair() },
+		"migrate": func(a []string) {
+			format, found := takeFlag(&a, "--to")
+			if !found || format == "" {
+				fmt.Println("Usage: lgrt migrate --to sqlite|json [file]")
+				return
+			}
+			target := ""
+			if len(a) > 0 {
+				target = a[0]
+			}
+			logic.Migrate(format, target)
+		},
+		"fsck": func(a []string) {
+			var opts logic.FsckOptions
+			if fix, found := takeFlag(&a, "--fix"); found {
+				switch fix {
+				case "", "all":
+					opts = logic.FsckOptions{Orphans: true, Tags: true}
+				case "orphans":
+					opts.Orphans = true
+				case "tags":
+					opts.Tags = true
+				default:
+					fmt.Println("Usage: lgrt fsck [--fix [all|orphans|tags]]")
+					return
+				}
+			}
+			logic.Fsck(opts)
+		},
+		"export": func(a []string) {
+			warehouse, _ := takeFlag(&a, "--warehouse")
+			room, _ := takeFlag(&a, "--room")
+			if requireArgs(1, a) {
+				logic.Export(a[0], warehouse, room)
+			}
+		},
+		"import": func(a []string) {
+			if len(a) == 0 || a[0] != "csv" {
+				dryRun := takeSwitch(&a, "--dry-run")
+				strategy, found := takeFlag(&a, "--on-conflict")
+				if !found {
+					strategy = logic.ConflictMerge
+				}
+				if requireArgs(1, a) {
+					logic.Import(a[0], strategy, dryRun)
+				}
+				return
+			}
+			a = a[1:]
+			opts := logic.CSVImportOptions{DryRun: takeSwitch(&a, "--dry-run"), Create: takeSwitch(&a, "--create")}
+			if mapping, found := takeFlag(&a, "--map"); found {
+				opts.Mapping = map[string]string{}
+				for _, pair := range strings.Split(mapping, ",") {
+					field, column, ok := strings.Cut(pair, "=")
+					if !ok {
+						fmt.Printf("Error: \"%s\" is not field=column\n", pair)
+						return
+					}
+					opts.Mapping[strings.ToLower(strings.TrimSpace(field))] = strings.TrimSpace(column)
+				}
+			}
+			if requireArgs(1, a) {
+				logic.ImportCSV(a[0], opts)
+			}
+		},
+		"view": func(a []string) {
+			if len(a) == 0 || a[0] == "list" {
+				logic.ListViews()
+				return
+			}
+			switch a[0] {
+			case "save":
+				if requireArgs(3, a) {
+					logic.SaveView(a[1], strings.Join(a[2:], " "))
+				}
+			case "run":
+				sortname := takeSwitch(&a, "--sort")
+				if requireArgs(2, a) {
+					logic.RunView(a[1], sortname)
+				}
+			case "rm":
+				if requireArgs(2, a) {
+					logic.DeleteView(a[1])
+				}
+			default:
+				fmt.Printf("Unknown view operation \"%s\"\n", a[0])
+			}
+		},
+		"profile": func(a []string) {
+			if len(a) == 0 || a[0] == "list" {
+				logic.ListProfiles()
+				return
+			}
+			switch a[0] {
+			case "add":
+				if requireArgs(3, a) {
+					logic.AddProfile(a[1], a[2])
+				}
+			case "use":
+				if requireArgs(2, a) {
+					logic.UseProfile(a[1])
+				}
+			case "rm":
+				if requireArgs(2, a) {
+					logic.RemoveProfile(a[1])
+				}
+			default:
+				fmt.Printf("Unknown profile operation \"%s\"\n", a[0])
+			}
+		},
 		"sww": func(a []string) {
 			if requireArgs(1, a) {
 				logic.SwitchWarehouse(a[0])
@@ -145,8 +414,8 @@ func ProcessArgs() {
 		"lss": func(_ []string) { data.Db.Shelves.PrintList(true) },
 		"lb":  func(_ []string) { data.Db.Boxes.PrintList(false) },
 		"lbs": func(_ []string) { data.Db.Boxes.PrintList(true) },
-		"li":  func(_ []string) { data.Db.Items.PrintList(false) },
-		"lis": func(_ []string) { data.Db.Items.PrintList(true) },
+		"li":  func(a []string) { listItems(a, false) },
+		"lis": func(a []string) { listItems(a, true) },
 		"lt":  func(_ []string) { data.Db.Tags.PrintList(true) },
 		"lic": func(a []string) {
 			if requireArgs(1, a) {
@@ -228,47 +497,55 @@ func ProcessArgs() {
 			}
 		},
 		"d": func(a []string) {
-			if requireArgs(1, a) {
+			opts, ok := deleteOptions(&a)
+			if ok && requireArgs(1, a) {
 				id, ok := parseID(a[0], "Error: not an ID")
 				if !ok {
 					return
 				}
-				logic.DeleteAny(id)
+				logic.DeleteAny(id, opts)
 			}
 		},
 		"dc": func(a []string) {
-			if requireArgs(1, a) {
-				logic.DeleteSet[data.Category](&data.Db.Categories, a[0], "Category")
+			opts, ok := deleteOptions(&a)
+			if ok && requireArgs(1, a) {
+				logic.DeleteSet[data.Category](&data.Db.Categories, a[0], "Category", opts)
 			}
 		},
 		"dw": func(a []string) {
-			if requireArgs(1, a) {
-				logic.DeleteSet[data.Warehouse](&data.Db.Warehouses, a[0], "Warehouse")
+			opts, ok := deleteOptions(&a)
+			if ok && requireArgs(1, a) {
+				logic.DeleteSet[data.Warehouse](&data.Db.Warehouses, a[0], "Warehouse", opts)
 			}
 		},
 		"dr": func(a []string) {
-			if requireArgs(1, a) {
-				logic.DeleteSet[data.Room](&data.Db.Rooms, a[0], "Room")
+			opts, ok := deleteOptions(&a)
+			if ok && requireArgs(1, a) {
+				logic.DeleteSet[data.Room](&data.Db.Rooms, a[0], "Room", opts)
 			}
 		},
 		"ds": func(a []string) {
-			if requireArgs(1, a) {
-				logic.DeleteSet[data.Shelf](&data.Db.Shelves, a[0], "Shelf")
+			opts, ok := deleteOptions(&a)
+			if ok && requireArgs(1, a) {
+				logic.DeleteSet[data.Shelf](&data.Db.Shelves, a[0], "Shelf", opts)
 			}
 		},
 		"db": func(a []string) {
-			if requireArgs(1, a) {
-				logic.DeleteSet[data.Box](&data.Db.Boxes, a[0], "Box")
+			opts, ok := deleteOptions(&a)
+			if ok && requireArgs(1, a) {
+				logic.DeleteSet[data.Box](&data.Db.Boxes, a[0], "Box", opts)
 			}
 		},
 		"di": func(a []string) {
-			if requireArgs(1, a) {
-				logic.DeleteSet[data.Item](&data.Db.Items, a[0], "Item")
+			opts, ok := deleteOptions(&a)
+			if ok && requireArgs(1, a) {
+				logic.DeleteSet[data.Item](&data.Db.Items, a[0], "Item", opts)
 			}
 		},
 		"dt": func(a []string) {
-			if requireArgs(1, a) {
-				logic.DeleteSet[data.Tag](&data.Db.Tags, a[0], "Tag")
+			opts, ok := deleteOptions(&a)
+			if ok && requireArgs(1, a) {
+				logic.DeleteSet[data.Tag](&data.Db.Tags, a[0], "Tag", opts)
 			}
 		},
 		"s": func(a []string) {
@@ -310,38 +587,248 @@ func ProcessArgs() {
 				logic.ShowSet[data.Item](&data.Db.Items, a[0], "Item")
 			}
 		},
-		"f": func(a []string) {
+		"undo": func(_ []string) { logic.Undo() },
+		"redo": func(_ []string) { logic.Redo() },
+		"journal": func(a []string) {
+			count := 20
+			if len(a) > 0 {
+				n, err := strconv.Atoi(a[0])
+				if err != nil {
+					fmt.Println("Error: not a number")
+					return
+				}
+				count = n
+			}
+			logic.PrintJournal(count)
+		},
+		"trash": func(_ []string) { logic.PrintTrash() },
+		"restore": func(a []string) {
 			if requireArgs(1, a) {
-				data.Db.FindItem(a[0], false)
+				id, ok := parseID(a[0], "Error: not an ID")
+				if !ok {
+					return
+				}
+				logic.Restore(id)
 			}
 		},
-		"fs": func(a []string) {
+		"purge": func(a []string) {
+			var age time.Duration
+			if value, found := takeFlag(&a, "--older-than"); found {
+				var err error
+				if age, err = parseAge(value); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+			}
+			logic.Purge(age)
+		},
+		"take": func(a []string) {
+			if itemid, n, reason, ok := amountArgs(a); ok {
+				logic.Take(itemid, n, reason)
+			}
+		},
+		"put": func(a []string) {
+			if itemid, n, reason, ok := amountArgs(a); ok {
+				logic.Put(itemid, n, reason)
+			}
+		},
+		"lend": func(a []string) {
+			var due time.Time
+			if value, found := takeFlag(&a, "--due"); found {
+				var err error
+				if due, err = parseDue(value); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+			}
+			if requireArgs(2, a) {
+				if itemid, ok := parseID(a[0], "Error: Not an Id"); ok {
+					logic.Lend(itemid, strings.Join(a[1:], " "), due)
+				}
+			}
+		},
+		"return": func(a []string) {
 			if requireArgs(1, a) {
-				data.Db.FindItem(a[0], true)
+				if itemid, ok := parseID(a[0], "Error: Not an Id"); ok {
+					logic.Return(itemid)
+				}
+			}
+		},
+		"lent": func(_ []string) { logic.ListLent() },
+		"tui": func(_ []string) {
+			if err := tui.Run(); err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+		},
+		"expiring": func(a []string) {
+			days := 30
+			if value, found := takeFlag(&a, "--within"); found {
+				period, err := parseAge(value)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				days = int(period.Hours() / 24)
+			}
+			logic.Expiring(days)
+		},
+		"expired": func(_ []string) { logic.Expired() },
+		"value":   func(_ []string) { logic.Value() },
+		"warranty": func(a []string) {
+			days := 60
+			if value, found := takeFlag(&a, "--within"); found {
+				period, err := parseAge(value)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				days = int(period.Hours() / 24)
+			}
+			logic.Warranty(days)
+		},
+		"low": func(a []string) {
+			check := takeSwitch(&a, "--check")
+			if logic.LowStock() > 0 && check {
+				ExitCode = 1
+			}
+		},
+		"ledger": func(a []string) {
+			var since time.Time
+			if value, found := takeFlag(&a, "--since"); found {
+				var err error
+				if since, err = parseSince(value); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+			}
+			if requireArgs(1, a) {
+				if itemid, ok := parseID(a[0], "Error: Not an Id"); ok {
+					logic.ShowLedger(itemid, since)
+				}
+			}
+		},
+		"f": func(a []string) {
+			fuzzy := takeSwitch(&a, "--fuzzy")
+			if !requireArgs(1, a) {
+				return
+			}
+			if fuzzy {
+				data.Db.FindItemFuzzy(strings.Join(a, " "), false)
+			} else {
+				data.Db.FindItem(strings.Join(a, " "), false)
+			}
+		},
+		"fs": func(a []string) {
+			fuzzy := takeSwitch(&a, "--fuzzy")
+			if !requireArgs(1, a) {
+				return
+			}
+			if fuzzy {
+				data.Db.FindItemFuzzy(strings.Join(a, " "), true)
+			} else {
+				data.Db.FindItem(strings.Join(a, " "), true)
 			}
 		},
 	}
 
-	if handler, ok := handlers[cmd]; ok {
-		handler(rest)
+	handler, ok := handlers[cmd]
+	if !ok {
+		fmt.Printf("Unknown operation \"%s\"\n", cmd)
+		return
+	}
+	if !dblessCommands[cmd] && !logic.LoadDatabase() {
 		return
 	}
-	fmt.Printf("Unknown operation \"%s\"\n", cmd)
+	if view != "" && !logic.UseView(view) {
+		return
+	}
+	handler(rest)
+	data.CloseStore()
+}
+
+// amountArgs parses "<itemId> [n] [--reason text]" of take and put, n defaults to 1.
+func amountArgs(args []string) (itemid uint32, n int, reason string, ok bool) {
+	reason, _ = takeFlag(&args, "--reason")
+	if !requireArgs(1, args) {
+		return 0, 0, "", false
+	}
+	if itemid, ok = parseID(args[0], "Error: Not an Id"); !ok {
+		return 0, 0, "", false
+	}
+	n = 1
+	if len(args) > 1 {
+		var err error
+		if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+			fmt.Printf("Error: \"%s\" is not a positive number\n", args[1])
+			return 0, 0, "", false
+		}
+	}
+	return itemid, n, reason, true
+}
+
+// parseSince parses a date like 2024-05-31 or an age like 30d as a point in time.
+func parseSince(arg string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, arg, time.Local); err == nil {
+		return t, nil
+	}
+	age, err := parseAge(arg)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date \"%s\", use YYYY-MM-DD or an age like 30d", arg)
+	}
+	return time.Now().Add(-age), nil
+}
+
+// listItems lists all items, with --warranty only those under warranty.
+func listItems(args []string, sortname bool) {
+	if takeSwitch(&args, "--warranty") {
+		data.Db.Items.PrintListFiltered(sortname, func(set data.Dataset[data.Item]) bool {
+			return set.Data.UnderWarranty()
+		})
+		return
+	}
+	data.Db.Items.PrintList(sortname)
+}
+
+// parseDue parses a date like 2024-05-31 or a period like 14d from today.
+func parseDue(arg string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, arg, time.Local); err == nil {
+		return t, nil
+	}
+	period, err := parseAge(arg)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date \"%s\", use YYYY-MM-DD or a period like 14d", arg)
+	}
+	return time.Now().Add(period), nil
 }
 
 // PrintUsage prints CLI usage text.
 func PrintUsage() {
 	fmt.Println(terminal.GetHeadlineText(appName + " - console inventory management"))
-	fmt.Println("usage: lgrt <operation> [object|list|id|name|searchstring]")
+	fmt.Println("usage: lgrt [--db <file>|--profile <name>] [--output text|json|csv|tsv] [--view <name>] <operation> [object|list|id|name|searchstring]")
 	fmt.Println()
 	fmt.Println(terminal.GetHeadlineText("Operations: "))
 	fmt.Println()
+	fmt.Println("tui        browse the inventory full-screen: tree of warehouses, rooms, shelves and boxes,")
+	fmt.Println("           their items, details and live search. Keys: Tab pane, / search, a add items,")
+	fmt.Println("           n new, e edit, m move, d delete, t/T tag/untag, +/- put/take, q quit")
+	fmt.Println()
 	fmt.Println(terminal.GetHeadlineText("Add objects:"))
 	fmt.Println("aw  <name>                       Add a warehouse")
 	fmt.Println("ar  <name>                       Add a room to the current warehouse")
 	fmt.Println("as  <shelf name or id> <room name or ID> Add a shelf to a room")
 	fmt.Println("ab  <box name or ID> <shelf name or ID>  Add a box to a shelf")
 	fmt.Println("ai  <box name or ID>             add items interactively to a specific box")
+	fmt.Println("import csv <file> [--dry-run] [--create] [--map field=column,...]")
+	fmt.Println("                                 add items from a CSV file with a header line. Columns:")
+	fmt.Println("                                 name, description, location, condition, amount,")
+	fmt.Println("                                 box (name, id or [warehouse/]room/shelf/box), category, tags,")
+	fmt.Println("                                 expires, purchased (YYYY-MM-DD or DD.MM.YYYY), price, currency,")
+	fmt.Println("                                 vendor, serial, warranty (until YYYY-MM-DD), receipt")
+	fmt.Println("                                 --create adds missing boxes, shelves and rooms of a path")
+	fmt.Println("import <file> [--on-conflict merge|rename|skip] [--dry-run]")
+	fmt.Println("                                 add the contents of a file written by export, all records get")
+	fmt.Println("                                 new ids. On a name already used in the same place merge uses the")
+	fmt.Println("                                 existing object, rename adds a number, skip leaves it out")
 	fmt.Println("sww <name>                       switch to warehouse")
 	fmt.Println()
 	fmt.Println(terminal.GetHeadlineText("List objects:"))
@@ -355,8 +842,8 @@ func PrintUsage() {
 	fmt.Println("lss    list shelves sorted by name")
 	fmt.Println("lb     list boxes")
 	fmt.Println("lbs    list boxes sorted by name")
-	fmt.Println("li     list items")
-	fmt.Println("lis    list items sorted by name")
+	fmt.Println("li     list items, --warranty only items under warranty")
+	fmt.Println("lis    list items sorted by name, --warranty only items under warranty")
 	fmt.Println("lic    <category name or id> list items of a category")
 	fmt.Println("lics   <category name or id> list items of a category sorted by name")
 	fmt.Println("lib    <box name or id>      list items in a box")
@@ -374,6 +861,22 @@ func PrintUsage() {
 	fmt.Println("mi <itemid> <box name or id>   move item to another box")
 	fmt.Println("mb <boxid>  <shelf name or id> move box to another shelf")
 	fmt.Println()
+	fmt.Println(terminal.GetHeadlineText("Stock:"))
+	fmt.Println("take <itemid> [n] [--reason <text>]  take n pieces (default 1), never below 0")
+	fmt.Println("put  <itemid> [n] [--reason <text>]  put n pieces back (default 1)")
+	fmt.Println("ledger <itemid> [--since <date|n>d]  list the changes of the amount, si shows the newest")
+	fmt.Println("low [--check]                        list items at or below their minimum amount by category,")
+	fmt.Println("                                     --check exits with status 1 if there are any")
+	fmt.Println("expiring [--within <n>d]             list items expiring within n days (default 30)")
+	fmt.Println("expired                              list items past their expiry date")
+	fmt.Println("value                                sum amount x unit price per warehouse, room, box and category")
+	fmt.Println("warranty [--within <n>d]             list items whose warranty ends within n days (default 60)")
+	fmt.Println()
+	fmt.Println(terminal.GetHeadlineText("Lending:"))
+	fmt.Println("lend <itemid> <person> [--due <date|n>d]  record who borrowed an item")
+	fmt.Println("return <itemid>                          record that a lent item is back")
+	fmt.Println("lent                                     list lent items, overdue ones in red")
+	fmt.Println()
 	fmt.Println(terminal.GetHeadlineText("Delete objects:"))
 	fmt.Println("d <id>     delete object")
 	fmt.Println("dc <name|id>  delete category")
@@ -382,6 +885,12 @@ func PrintUsage() {
 	fmt.Println("ds <name|id>  delete shelf")
 	fmt.Println("db <name|id>  delete box")
 	fmt.Println("deleting objects won't break integrity, since they are only marked as deleted.")
+	fmt.Println("Objects with contents ask whether to delete or move the contents, or use")
+	fmt.Println("--cascade                     delete the contents as well")
+	fmt.Println("--reparent <name|id>          move the contents to another object of the same type")
+	fmt.Println("trash                         list deleted objects")
+	fmt.Println("restore <id>                  restore a deleted object and its deleted parents")
+	fmt.Println("purge [--older-than <n>d]     remove deleted objects for good")
 	fmt.Println()
 	fmt.Println(terminal.GetHeadlineText("Tagging:"))
 	fmt.Println("Tags are automatically added on first use.")
@@ -401,7 +910,47 @@ func PrintUsage() {
 	fmt.Println("sb <name|id>  show box")
 	fmt.Println()
 	fmt.Println(terminal.GetHeadlineText("Find items:"))
-	fmt.Println("f  <searchstring>  list sorted by Id")
-	fmt.Println("fs <searchstring>  list sorted by name")
+	fmt.Println("f  <query>  list sorted by Id")
+	fmt.Println("fs <query>  list sorted by name")
+	fmt.Println("           words and \"phrases\" are searched in name, description and location,")
+	fmt.Println("           fields: name: desc: loc: tag: cat: box: shelf: room: warehouse: lent: vendor:")
+	fmt.Println("           serial: condition: id amount min price created updated expires purchased warranty")
+	fmt.Println("           with : = < <= > >=, combine with AND, OR, NOT or -, group with ( )")
+	fmt.Println("f --fuzzy <words>  tolerate typos, rank items by relevance and highlight the match")
+	fmt.Println()
+	fmt.Println(terminal.GetHeadlineText("Undo changes:"))
+	fmt.Println("undo         undo the last change")
+	fmt.Println("redo         redo the last undone change")
+	fmt.Println("journal [n]  list the last n changes (default 20)")
+	fmt.Println()
+	fmt.Println(terminal.GetHeadlineText("Maintenance:"))
+	fmt.Println("repair     salvage a corrupted database, reports dropped records")
+	fmt.Println("migrate --to sqlite|json [file]  copy the database to another storage backend")
+	fmt.Println("fsck       check references, ids and amounts")
+	fmt.Println("fsck --fix [all|orphans|tags]  move orphans to \"Lost & Found\", strip dangling tags")
+	fmt.Println()
+	fmt.Println(terminal.GetHeadlineText("Inventories:"))
+	fmt.Println("--db <file>                global option: use this database file")
+	fmt.Println("--profile <name>           global option: use a registered inventory")
+	fmt.Println("profile [list]             list registered inventories")
+	fmt.Println("profile add <name> <file>  register an inventory file")
+	fmt.Println("profile use <name>         switch to an inventory")
+	fmt.Println("profile rm  <name>         unregister an inventory, the file is kept")
+	fmt.Println("The database can also be set with the " + config.EnvDb + " environment variable.")
+	fmt.Println("export [--warehouse <name>] [--room <name>] <file>")
+	fmt.Println("                           write all warehouses, one warehouse or one room with their")
+	fmt.Println("                           contents, categories and tags to a file for import")
+	fmt.Println()
+	fmt.Println(terminal.GetHeadlineText("Views:"))
+	fmt.Println("view save <name> <query>   save a search query of f, e.g. tag:broken for lit broken")
+	fmt.Println("                           or cat:tools for lic tools")
+	fmt.Println("view run <name> [--sort]   list the items of a view")
+	fmt.Println("view [list]                list the saved views with their number of items")
+	fmt.Println("view rm <name>             delete a view")
+	fmt.Println("--view <name>              global option: limit all item lists and reports to a view, e.g. lgrt --view broken lic tools")
+	fmt.Println()
+	fmt.Println(terminal.GetHeadlineText("Scripting:"))
+	fmt.Println("--output json|csv|tsv      global option: print lists, search results and details")
+	fmt.Println("                           machine-readable with resolved parent names and ids")
 
 }
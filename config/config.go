@@ -0,0 +1,199 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const dbFileName = "lgrtdata.json"
+const profileFileName = "profiles.json"
+
+// EnvDb is the environment variable that overrides the database location.
+const EnvDb = "LGRT_DB"
+
+type Profiles struct {
+	Active   string            `json:"active"`
+	Profiles map[string]string `json:"profiles"`
+}
+
+type ProfileEntry struct {
+	Name   string
+	Path   string
+	Active bool
+}
+
+// Dir returns the directory holding the configuration and the default database.
+// An existing ~/.lgrt is kept for compatibility, otherwise the XDG config dir is used
+// when XDG_CONFIG_HOME is set or ~/.config/lgrt already exists.
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locate home directory: %w", err)
+	}
+	legacy := filepath.Join(home, ".lgrt")
+	if isDir(legacy) {
+		return legacy, nil
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "lgrt"), nil
+	}
+	if xdgdefault := filepath.Join(home, ".config", "lgrt"); isDir(xdgdefault) {
+		return xdgdefault, nil
+	}
+	return legacy, nil
+}
+
+// isDir reports whether path exists and is a directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// DefaultDbPath returns the database location used without flag, env or profile.
+func DefaultDbPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, dbFileName), nil
+}
+
+// ExpandPath resolves a leading ~ and makes the path absolute.
+func ExpandPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("locate home directory: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	return filepath.Abs(path)
+}
+
+// ResolveDbPath picks the database location. Precedence is the --db flag,
+// the --profile flag, the LGRT_DB environment variable, the active profile
+// and finally the default location. The profile registry is only read when
+// a profile is needed.
+func ResolveDbPath(flagPath string, profile string) (string, error) {
+	if flagPath != "" {
+		return ExpandPath(flagPath)
+	}
+	if profile != "" {
+		profiles, err := LoadProfiles()
+		if err != nil {
+			return "", err
+		}
+		path, ok := profiles.Profiles[profile]
+		if !ok {
+			return "", fmt.Errorf("unknown profile \"%s\"", profile)
+		}
+		return path, nil
+	}
+	if env := os.Getenv(EnvDb); env != "" {
+		return ExpandPath(env)
+	}
+	profiles, err := LoadProfiles()
+	if err != nil {
+		return "", err
+	}
+	if path, ok := profiles.Profiles[profiles.Active]; ok {
+		return path, nil
+	}
+	return DefaultDbPath()
+}
+
+// profilePath returns the location of the profile registry.
+func profilePath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profileFileName), nil
+}
+
+// LoadProfiles reads the profile registry, a missing file yields an empty one.
+func LoadProfiles() (*Profiles, error) {
+	p := &Profiles{Profiles: map[string]string{}}
+	path, err := profilePath()
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bytes, p); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if p.Profiles == nil {
+		p.Profiles = map[string]string{}
+	}
+	return p, nil
+}
+
+// Save writes the profile registry.
+func (p *Profiles) Save() error {
+	path, err := profilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	bytes, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bytes, 0644)
+}
+
+// Add registers or replaces a named database file.
+func (p *Profiles) Add(name string, path string) error {
+	abs, err := ExpandPath(path)
+	if err != nil {
+		return err
+	}
+	p.Profiles[name] = abs
+	return nil
+}
+
+// Use makes a registered profile the active one.
+func (p *Profiles) Use(name string) error {
+	if _, ok := p.Profiles[name]; !ok {
+		return fmt.Errorf("unknown profile \"%s\"", name)
+	}
+	p.Active = name
+	return nil
+}
+
+// Remove unregisters a profile, the database file itself is kept.
+func (p *Profiles) Remove(name string) error {
+	if _, ok := p.Profiles[name]; !ok {
+		return fmt.Errorf("unknown profile \"%s\"", name)
+	}
+	delete(p.Profiles, name)
+	if p.Active == name {
+		p.Active = ""
+	}
+	return nil
+}
+
+// List returns all profiles sorted by name.
+func (p *Profiles) List() []ProfileEntry {
+	var list []ProfileEntry
+	for name, path := range p.Profiles {
+		list = append(list, ProfileEntry{Name: name, Path: path, Active: name == p.Active})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setHome points HOME at a fresh directory and clears related variables.
+func setHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(EnvDb, "")
+	return home
+}
+
+// TestDir verifies the legacy and XDG directory selection.
+func TestDir(t *testing.T) {
+	home := setHome(t)
+	if dir, _ := Dir(); dir != filepath.Join(home, ".lgrt") {
+		t.Fatalf("expected legacy default, got %s", dir)
+	}
+
+	xdg := filepath.Join(home, "xdg")
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	if dir, _ := Dir(); dir != filepath.Join(xdg, "lgrt") {
+		t.Fatalf("expected XDG dir, got %s", dir)
+	}
+
+	_ = os.Mkdir(filepath.Join(home, ".lgrt"), os.ModePerm)
+	if dir, _ := Dir(); dir != filepath.Join(home, ".lgrt") {
+		t.Fatalf("expected existing legacy dir to win, got %s", dir)
+	}
+}
+
+// TestResolveDbPath verifies flag, environment and profile precedence.
+func TestResolveDbPath(t *testing.T) {
+	home := setHome(t)
+
+	path, err := ResolveDbPath("", "")
+	if err != nil || path != filepath.Join(home, ".lgrt", dbFileName) {
+		t.Fatalf("unexpected default path %s (%v)", path, err)
+	}
+
+	profiles, _ := LoadProfiles()
+	_ = profiles.Add("workshop", filepath.Join(home, "workshop.json"))
+	_ = profiles.Add("club", "~/club.json")
+	_ = profiles.Use("workshop")
+	if err := profiles.Save(); err != nil {
+		t.Fatalf("save profiles: %v", err)
+	}
+
+	if path, _ := ResolveDbPath("", ""); path != filepath.Join(home, "workshop.json") {
+		t.Fatalf("expected active profile, got %s", path)
+	}
+	if path, _ := ResolveDbPath("", "club"); path != filepath.Join(home, "club.json") {
+		t.Fatalf("expected selected profile, got %s", path)
+	}
+	if _, err := ResolveDbPath("", "missing"); err == nil {
+		t.Fatalf("expected error for unknown profile")
+	}
+
+	t.Setenv(EnvDb, filepath.Join(home, "env.json"))
+	if path, _ := ResolveDbPath("", ""); path != filepath.Join(home, "env.json") {
+		t.Fatalf("expected environment to win over the active profile, got %s", path)
+	}
+	if path, _ := ResolveDbPath("", "club"); path != filepath.Join(home, "club.json") {
+		t.Fatalf("expected --profile to win over the environment, got %s", path)
+	}
+	if path, _ := ResolveDbPath(filepath.Join(home, "flag.json"), ""); path != filepath.Join(home, "flag.json") {
+		t.Fatalf("expected flag to win, got %s", path)
+	}
+
+	// a broken registry only matters when a profile is needed
+	registry, _ := profilePath()
+	if err := os.WriteFile(registry, []byte("{broken"), 0644); err != nil {
+		t.Fatalf("write registry: %v", err)
+	}
+	if path, err := ResolveDbPath(filepath.Join(home, "flag.json"), ""); err != nil || path != filepath.Join(home, "flag.json") {
+		t.Fatalf("expected --db to work with a broken registry, got %s (%v)", path, err)
+	}
+	if path, err := ResolveDbPath("", ""); err != nil || path != filepath.Join(home, "env.json") {
+		t.Fatalf("expected LGRT_DB to work with a broken registry, got %s (%v)", path, err)
+	}
+	if _, err := ResolveDbPath("", "club"); err == nil {
+		t.Fatal("expected an error for --profile with a broken registry")
+	}
+}
+
+// TestProfilesRemove verifies that removing the active profile clears it.
+func TestProfilesRemove(t *testing.T) {
+	setHome(t)
+	profiles, _ := LoadProfiles()
+	_ = profiles.Add("home", "/tmp/home.json")
+	_ = profiles.Use("home")
+	if err := profiles.Remove("home"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if profiles.Active != "" || len(profiles.List()) != 0 {
+		t.Fatalf("expected empty registry, got %+v", profiles)
+	}
+	if err := profiles.Remove("home"); err == nil {
+		t.Fatalf("expected error for unknown profile")
+	}
+	if err := profiles.Use("home"); err == nil {
+		t.Fatalf("expected error for unknown profile")
+	}
+}
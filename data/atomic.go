@@ -0,0 +1,82 @@
+package data
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// BackupGenerations is the number of previous database files kept by Save.
+var BackupGenerations = 5
+
+// BackupPath returns the file name of backup generation n for path.
+func BackupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// writeFileAtomic writes data to a temp file, syncs it and renames it over path.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpname := tmp.Name()
+	// the temp file must not survive a failed write
+	defer os.Remove(tmpname)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpname, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpname, path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes directory metadata so a rename survives a crash.
+// Errors are ignored because not every platform supports syncing directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
+
+// rotateBackups shifts path.1..path.n-1 up by one and copies path to path.1.
+func rotateBackups(path string, generations int) error {
+	if generations < 1 {
+		return nil
+	}
+	current, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(BackupPath(path, generations)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for n := generations - 1; n >= 1; n-- {
+		err := os.Rename(BackupPath(path, n), BackupPath(path, n+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return writeFileAtomic(BackupPath(path, 1), current, 0644)
+}
@@ -0,0 +1,125 @@
+package data
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/elsni/lagerator/id"
+)
+
+const benchItems = 100000
+
+// fillBenchDb fills the global database with a synthetic hierarchy and benchItems items.
+func fillBenchDb() {
+	resetDb()
+	wh := NewDataset[Warehouse]("Warehouse", Warehouse{})
+	Db.Warehouses.Add(wh)
+	Db.CurrentWarehouse = wh.ID
+	var boxes []uint32
+	for r := 0; r < 10; r++ {
+		room := NewDataset[Room](fmt.Sprintf("Room %d", r), Room{WarehouseId: wh.ID})
+		Db.Rooms.Add(room)
+		for s := 0; s < 10; s++ {
+			shelf := NewDataset[Shelf](fmt.Sprintf("Shelf %d-%d", r, s), Shelf{RoomId: room.ID})
+			Db.Shelves.Add(shelf)
+			for b := 0; b < 10; b++ {
+				box := NewDataset[Box](fmt.Sprintf("Box %d-%d-%d", r, s, b), Box{ShelfId: shelf.ID})
+				Db.Boxes.Add(box)
+				boxes = append(boxes, box.ID)
+			}
+		}
+	}
+	var categories []uint32
+	for c := 0; c < 50; c++ {
+		categories = append(categories, Db.Categories.AddSimple(fmt.Sprintf("Category %d", c)))
+	}
+	for i := 0; i < benchItems; i++ {
+		item := NewDataset[Item](fmt.Sprintf("Item %d", i), Item{
+			Amount:     i % 7,
+			Location:   fmt.Sprintf("Slot %d", i%13),
+			BoxId:      boxes[i%len(boxes)],
+			CategoryId: categories[i%len(categories)],
+		})
+		item.Description = fmt.Sprintf("synthetic item number %d", i)
+		Db.Items.Add(item)
+	}
+}
+
+// discardStdout redirects stdout to /dev/null until the returned function is called.
+func discardStdout(b *testing.B) func() {
+	b.Helper()
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatalf("open %s: %v", os.DevNull, err)
+	}
+	old := os.Stdout
+	os.Stdout = null
+	return func() {
+		os.Stdout = old
+		null.Close()
+	}
+}
+
+// BenchmarkListItems measures "lgrt li" including name resolution of all parents.
+func BenchmarkListItems(b *testing.B) {
+	fillBenchDb()
+	restore := discardStdout(b)
+	defer restore()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Db.Items.PrintList(false)
+	}
+}
+
+// BenchmarkFindItem measures "lgrt f" over all items with a current search index.
+func BenchmarkFindItem(b *testing.B) {
+	fillBenchDb()
+	Db.SearchIndex()
+	restore := discardStdout(b)
+	defer restore()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Db.FindItem("item 4242", false)
+	}
+}
+
+// BenchmarkBuildSearchIndex measures indexing all items.
+func BenchmarkBuildSearchIndex(b *testing.B) {
+	fillBenchDb()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix := newSearchIndex(0)
+		ix.refresh(Db, nil)
+	}
+}
+
+// BenchmarkGetIdx measures id lookups.
+func BenchmarkGetIdx(b *testing.B) {
+	fillBenchDb()
+	last := id.IdSource.LastId
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Db.Items.GetIdx(last - uint32(i%benchItems))
+	}
+}
+
+// BenchmarkGetSetsByName measures case-insensitive name lookups.
+func BenchmarkGetSetsByName(b *testing.B) {
+	fillBenchDb()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Db.Items.GetSetsByName(fmt.Sprintf("ITEM %d", i%benchItems))
+	}
+}
+
+// BenchmarkCheck measures the integrity check over benchItems items.
+func BenchmarkCheck(b *testing.B) {
+	fillBenchDb()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if problems := Db.Check(); len(problems) != 0 {
+			b.Fatalf("expected no problems, got %d", len(problems))
+		}
+	}
+}
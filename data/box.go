@@ -22,6 +22,15 @@ func (d Box) GetTableRow(ownid uint32) string {
 	return fmt.Sprintf("%-20s %-20s %-20s", shelfName, roomName, whName)
 }
 
+// GetColumns returns the box fields with resolved container names.
+func (d Box) GetColumns(ownid uint32) []Column {
+	columns := []Column{
+		{"location", d.Location},
+		{"type", d.Type},
+	}
+	return append(columns, containerColumns(d.ShelfId)...)
+}
+
 // GetTableHeader returns the box table header.
 func (d Box) GetTableHeader() string {
 	return fmt.Sprintf("%-20s %-20s %-20s%s", "Shelf", "Room", "Warehouse", terminal.ResetColor())
@@ -53,4 +62,9 @@ func GetBoxNamesforShelf(st *BoxTable, sid uint32) []Listentry {
 	return names
 }
 
+// ShowExtra prints the value of the items in the box.
+func (d Box) ShowExtra(ownid uint32) {
+	showContainerValue(Ref{"boxes", ownid})
+}
+
 type BoxTable = DataTable[Box]
@@ -14,6 +14,11 @@ func (d Category) GetTableRow(ownid uint32) string {
 	return ""
 }
 
+// GetColumns returns no columns, categories have no fields.
+func (d Category) GetColumns(ownid uint32) []Column {
+	return nil
+}
+
 // GetTableHeader returns the category table header.
 func (d Category) GetTableHeader() string {
 	return terminal.ResetColor()
@@ -14,6 +14,7 @@ import (
 type CustomData interface {
 	GetTableHeader() string
 	GetTableRow(ownid uint32) string
+	GetColumns(ownid uint32) []Column
 	Show()
 }
 
@@ -24,6 +25,7 @@ type Dataset[T CustomData] struct {
 	Created     int64    `json:"created"`
 	Updated     int64    `json:"updated"`
 	Deleted     bool     `json:"deleted"`
+	DeletedAt   int64    `json:"deletedAt,omitempty"`
 	Tags        []uint32 `json:"tags"`
 	Data        T        `json:"data"`
 }
@@ -85,6 +87,10 @@ func (d Dataset[T]) GetTableHeader() string {
 
 // Show prints dataset details to stdout.
 func (d Dataset[T]) Show() {
+	if Output != OutputText {
+		WriteSets([]Dataset[T]{d})
+		return
+	}
 	t := strings.Split(fmt.Sprintf("%T", *new(T)), ".")
 	fmt.Println(terminal.GetHeadlineText(fmt.Sprintf("%-12s %-30s", "Type", t[1])))
 	fmt.Printf("%s %d\n", terminal.GetLabelText("Id"), d.ID)
@@ -102,6 +108,14 @@ func (d Dataset[T]) Show() {
 	fmt.Printf("%s %s\n", terminal.GetLabelText("Updated"), terminal.GetTimeString(d.Updated))
 	d.Data.Show()
 	fmt.Printf("%s %s\n", terminal.GetLabelText("Tags"), GetTagList(d.Tags))
+	if e, ok := any(d.Data).(extraShower); ok {
+		e.ShowExtra(d.ID)
+	}
+}
+
+// extraShower is implemented by data with details printed below the tags.
+type extraShower interface {
+	ShowExtra(ownid uint32)
 }
 
 type DataTable[T CustomData] []Dataset[T]
@@ -127,7 +141,8 @@ func (dt *DataTable[T]) GetNames() []Listentry {
 
 // GetFirstOccurance returns the first case-insensitive name match and its id.
 func (dt *DataTable[T]) GetFirstOccurance(name string) (uint32, bool) {
-	for _, set := range *dt {
+	for _, i := range dt.nameCandidates(name) {
+		set := &(*dt)[i]
 		if !set.Deleted && strings.EqualFold(set.Name, name) {
 			return set.ID, true
 		}
@@ -152,7 +167,8 @@ func (dt *DataTable[T]) GetNamesRaw() []string {
 // GetSetsByName returns all non-deleted datasets with the given name.
 func (dt *DataTable[T]) GetSetsByName(name string) []Dataset[T] {
 	list := make([]Dataset[T], 0, 128)
-	for _, set := range *dt {
+	for _, i := range dt.nameCandidates(name) {
+		set := (*dt)[i]
 		if strings.EqualFold(set.Name, name) && !set.Deleted {
 			list = append(list, set)
 		}
@@ -165,7 +181,28 @@ func (dt *DataTable[T]) Add(set Dataset[T]) {
 	if set.Name == "" {
 		set.Name = "Unnamed"
 	}
-	*dt = append(*dt, set)
+	dt.appendIndexed(set)
+}
+
+// Replace overwrites the dataset at idx and keeps the name index current.
+func (dt *DataTable[T]) Replace(idx int, set Dataset[T]) {
+	ix := dt.kept()
+	old := (*dt)[idx]
+	(*dt)[idx] = set
+	if ix == nil {
+		return
+	}
+	if old.ID != set.ID {
+		// the id index can't be patched reliably, rebuild on next lookup
+		dt.dropIndex()
+		return
+	}
+	if old.Name != set.Name {
+		ix.removeName(old.Name, idx)
+		key := strings.ToLower(set.Name)
+		ix.names[key] = append(ix.names[key], idx)
+		slices.Sort(ix.names[key])
+	}
 }
 
 // AddSimple adds a dataset with a name, new id, and default data.
@@ -179,7 +216,7 @@ func (dt *DataTable[T]) AddSimple(name string) uint32 {
 		Data:    *data,
 		Deleted: false,
 	}
-	*dt = append(*dt, set)
+	dt.appendIndexed(set)
 	return set.ID
 }
 
@@ -194,7 +231,8 @@ func (dt *DataTable[T]) GetPtr(id uint32) (*Dataset[T], bool) {
 
 // GetDataByName returns index and id for an exact name match.
 func (dt *DataTable[T]) GetDataByName(name string) (int, uint32) {
-	for i, set := range *dt {
+	for _, i := range dt.nameCandidates(name) {
+		set := &(*dt)[i]
 		if set.Name == name && !set.Deleted {
 			return i, set.ID
 		}
@@ -204,19 +242,31 @@ func (dt *DataTable[T]) GetDataByName(name string) (int, uint32) {
 
 // GetIdx returns the index for an id, or -1 if not found.
 func (st *DataTable[T]) GetIdx(id uint32) int {
-	for i, set := range *st {
-		if set.ID == id && !set.Deleted {
-			return i
+	ix := st.index()
+	if ix.dups {
+		for i, set := range *st {
+			if set.ID == id && !set.Deleted {
+				return i
+			}
 		}
+		return -1
+	}
+	if i, ok := ix.ids[id]; ok && !(*st)[i].Deleted {
+		return i
 	}
 	return -1
 }
 
 // Delete marks an item as deleted and updates the timestamp.
+// The index is kept since it covers deleted entries as well.
 func (st *DataTable[T]) Delete(id uint32) {
 	idx := st.GetIdx(id)
+	if idx < 0 {
+		return
+	}
 	(*st)[idx].Deleted = true
-	(*st)[idx].Updated = time.Now().Unix()
+	(*st)[idx].DeletedAt = time.Now().Unix()
+	(*st)[idx].Updated = (*st)[idx].DeletedAt
 }
 
 // PrintList prints the table, optionally sorted by name.
@@ -243,9 +293,15 @@ func (st *DataTable[T]) PrintListByTagId(tagid uint32, sortname bool) {
 	})
 }
 
-// PrintListFiltered prints entries that match a filter, optionally sorted by name.
+// PrintListFiltered prints entries that match a filter, optionally sorted by
+// name. Item lists are limited to the Scope.
 func (st *DataTable[T]) PrintListFiltered(sortname bool, filter func(Dataset[T]) bool) {
 	var t []Sortentry
+	filter = scoped(filter)
+	if Output != OutputText {
+		st.writeFiltered(sortname, filter)
+		return
+	}
 	if len(*st) == 0 {
 		return
 	}
@@ -268,3 +324,19 @@ func (st *DataTable[T]) PrintListFiltered(sortname bool, filter func(Dataset[T])
 		fmt.Println(entry.Text)
 	}
 }
+
+// writeFiltered prints the live entries that match a filter in the machine-readable Output format.
+func (st *DataTable[T]) writeFiltered(sortname bool, filter func(Dataset[T]) bool) {
+	var sets []Dataset[T]
+	for _, set := range *st {
+		if !set.Deleted && (filter == nil || filter(set)) {
+			sets = append(sets, set)
+		}
+	}
+	if sortname {
+		sort.SliceStable(sets, func(i, j int) bool {
+			return strings.ToUpper(sets[i].Name) < strings.ToUpper(sets[j].Name)
+		})
+	}
+	WriteSets(sets)
+}
@@ -2,72 +2,258 @@ package data
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"slices"
 	"strings"
 
+	"github.com/elsni/lagerator/config"
 	"github.com/elsni/lagerator/id"
 )
 
 type Database struct {
-	CurrentWarehouse uint32         `json:"currentWarehouseid"`
-	Warehouses       WarehouseTable `json:"warehouses"`
-	Rooms            RoomTable      `json:"rooms"`
-	Shelves          ShelfTable     `json:"shelves"`
-	Boxes            BoxTable       `json:"boxes"`
-	Items            ItemTable      `json:"items"`
-	Categories       CategoryTable  `json:"categories"`
-	Tags             TagTable       `json:"tags"`
+	SchemaVersion    int               `json:"schemaVersion"`
+	Revision         uint64            `json:"revision"`
+	CurrentWarehouse uint32            `json:"currentWarehouseid"`
+	Warehouses       WarehouseTable    `json:"warehouses"`
+	Rooms            RoomTable         `json:"rooms"`
+	Shelves          ShelfTable        `json:"shelves"`
+	Boxes            BoxTable          `json:"boxes"`
+	Items            ItemTable         `json:"items"`
+	Categories       CategoryTable     `json:"categories"`
+	Tags             TagTable          `json:"tags"`
+	Views            map[string]string `json:"views"` // saved search queries by name
+
+	// loadedRevision is the revision read by the last Load or written by the last Save
+	loadedRevision uint64
+	// loadedSchema is the schema version the file had before it was migrated
+	loadedSchema int
+	// indexes holds the lookup index of each table by table pointer, see index
+	indexes map[any]any
 }
 
 // NewDatabase creates a Database with empty tables.
 func NewDatabase() *Database {
 	return &Database{
-		Warehouses: NewDataTable[Warehouse](),
-		Rooms:      NewDataTable[Room](),
-		Shelves:    NewDataTable[Shelf](),
-		Boxes:      NewDataTable[Box](),
-		Items:      NewDataTable[Item](),
-		Categories: NewDataTable[Category](),
-		Tags:       NewDataTable[Tag](),
+		SchemaVersion: SchemaVersion,
+		loadedSchema:  SchemaVersion,
+		Warehouses:    NewDataTable[Warehouse](),
+		Rooms:         NewDataTable[Room](),
+		Shelves:       NewDataTable[Shelf](),
+		Boxes:         NewDataTable[Box](),
+		Items:         NewDataTable[Item](),
+		Categories:    NewDataTable[Category](),
+		Tags:          NewDataTable[Tag](),
 	}
 }
 
-// Save writes the database to the user config directory.
-func (db *Database) Save() {
-	dirname, _ := os.UserHomeDir()
-	os.Mkdir(dirname+"/.lgrt", os.ModePerm)
-	json, _ := json.Marshal(db)
-	os.WriteFile(dirname+"/.lgrt/lgrtdata.json", json, 0644)
+// dbFile overrides the default database location when set.
+var dbFile string
+
+// SetPath sets the database file used by Save and Load.
+func SetPath(path string) {
+	dbFile = path
+}
+
+// Path returns the location of the database file.
+func Path() (string, error) {
+	if dbFile != "" {
+		return dbFile, nil
+	}
+	return config.DefaultDbPath()
+}
+
+// Save writes the database to its store while holding the file lock.
+// It returns ErrConflict if another process saved since the last Load.
+// JSON files are written atomically and the previous file is kept
+// as the newest of BackupGenerations backups.
+func (db *Database) Save() error {
+	return db.save(false, nil, nil)
+}
+
+// SaveChanges is Save for a journaled change. If refs is not nil, the change
+// only touched these records and values, a SQLite store then writes just
+// them. op is appended to the journal while the file lock is still held,
+// a failure to do so is returned as ErrJournal after the database was saved.
+func (db *Database) SaveChanges(refs []Ref, op *Operation) error {
+	return db.save(false, refs, op)
 }
 
-// Load reads the database file and updates the id source.
-func (db *Database) Load() {
-	dirname, _ := os.UserHomeDir()
-	bytes, err := os.ReadFile(dirname + "/.lgrt/lgrtdata.json")
+// ForceSave writes the database without checking for concurrent changes.
+// It is used to replace a corrupted file with a repaired or restored database.
+func (db *Database) ForceSave() error {
+	return db.save(true, nil, nil)
+}
+
+// save writes the database, force skips the revision check. If refs is
+// not nil, only these records changed since the last Load or Save.
+// op is journaled if it is not nil.
+func (db *Database) save(force bool, refs []Ref, op *Operation) error {
+	s, err := currentStore()
 	if err != nil {
-		return
+		return err
+	}
+	lock, err := LockPath(s.Path())
+	if err != nil {
+		return fmt.Errorf("lock database: %w", err)
+	}
+	defer lock.Unlock()
+
+	stored, err := s.Revision()
+	switch {
+	case force:
+		stored = max(stored, db.Revision)
+	case err != nil:
+		return err
+	case stored != db.loadedRevision:
+		return ErrConflict
+	}
+	db.Revision = stored + 1
+	// a migrated or new database has to be written as a whole
+	if sq, ok := s.(*SQLiteStore); ok && refs != nil && stored > 0 && db.loadedSchema == SchemaVersion {
+		err = db.saveRecords(sq, refs)
+	} else {
+		var doc Document
+		if doc, err = db.encode(); err == nil {
+			err = s.Save(doc)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	db.loadedRevision = db.Revision
+	if op == nil {
+		return nil
+	}
+	if err := appendJournal(op); err != nil {
+		return fmt.Errorf("%w: %v", ErrJournal, err)
+	}
+	return nil
+}
+
+// saveRecords writes the given records and the revision in one transaction.
+// Records missing in the database are deleted from the store.
+func (db *Database) saveRecords(s *SQLiteStore, refs []Ref) error {
+	return s.Update(func() error {
+		for _, ref := range refs {
+			record, ok := db.GetRecord(ref.Table, ref.ID)
+			var err error
+			switch _, isValue := db.value(ref.Table); {
+			case isValue:
+				err = s.PutValue(ref.Table, record)
+			case ok:
+				err = s.Put(ref.Table, ref.ID, record)
+			default:
+				err = s.Delete(ref.Table, ref.ID)
+			}
+			if err != nil {
+				return fmt.Errorf("save %s %d: %w", ref.Table, ref.ID, err)
+			}
+		}
+		revision, err := json.Marshal(db.Revision)
+		if err != nil {
+			return err
+		}
+		return s.PutValue("revision", revision)
+	})
+}
+
+// Load reads the database from its store and updates the id source.
+// A missing file is not an error, a corrupted one returns a *LoadError.
+// Files of an older schema version are migrated, the original file is
+// kept at SchemaBackupPath until the migrated database is saved over it.
+func (db *Database) Load() error {
+	s, err := currentStore()
+	if err != nil {
+		return err
 	}
-	err = json.Unmarshal(bytes, db)
+	lock, err := LockPath(s.Path())
 	if err != nil {
-		panic("Data corrupted!")
+		return fmt.Errorf("lock database: %w", err)
+	}
+	defer lock.Unlock()
+
+	err = db.loadFrom(s)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if db.loadedSchema < SchemaVersion {
+		if err := backupBeforeMigration(s.Path(), db.loadedSchema); err != nil {
+			return fmt.Errorf("backup before schema migration: %w", err)
+		}
+	}
+	id.IdSource.SetLastId(db.FindLastId())
+	return nil
+}
+
+// LoadFile reads the database from path and updates the id source.
+// The database is left untouched when the file cannot be decoded.
+func (db *Database) LoadFile(path string) error {
+	s, err := OpenStore(path)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if err := db.loadFrom(s); err != nil {
+		return err
 	}
 	id.IdSource.SetLastId(db.FindLastId())
+	return nil
+}
+
+// SaveTo writes the database to another file, choosing the backend by extension.
+func (db *Database) SaveTo(path string) error {
+	s, err := OpenStore(path)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	doc, err := db.encode()
+	if err != nil {
+		return err
+	}
+	return s.Save(doc)
+}
+
+// NewestValidBackup returns the newest backup generation that decodes cleanly.
+func NewestValidBackup() (int, bool) {
+	path, err := Path()
+	if err != nil {
+		return 0, false
+	}
+	for n := 1; n <= BackupGenerations; n++ {
+		if NewDatabase().loadFrom(NewJSONStore(BackupPath(path, n))) == nil {
+			return n, true
+		}
+	}
+	return 0, false
 }
 
-// FindItem prints matching items for a search string.
-func (db *Database) FindItem(searchstring string, sortname bool) {
-	if len(Db.Items) == 0 {
+// LoadBackup replaces the database with backup generation n.
+func (db *Database) LoadBackup(n int) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	return db.LoadFile(BackupPath(path, n))
+}
+
+// FindItem prints the items matching a search query, see ParseQuery.
+func (db *Database) FindItem(query string, sortname bool) {
+	filter, err := ParseQuery(query)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if len(Db.Items) == 0 && Output == OutputText {
 		fmt.Println("no data")
 		return
 	}
-	s := strings.ToLower(searchstring)
-	Db.Items.PrintListFiltered(sortname, func(item Dataset[Item]) bool {
-		return strings.Contains(strings.ToLower(item.Name), s) ||
-			strings.Contains(strings.ToLower(item.Description), s) ||
-			strings.Contains(strings.ToLower(item.Data.Location), s)
-	})
+	Db.Items.PrintListFiltered(sortname, filter)
 }
 
 // FindLastId returns the highest id across all tables.
@@ -1,13 +1,21 @@
 package data
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/elsni/lagerator/id"
+	"github.com/elsni/lagerator/terminal"
 )
 
 // TestMain sets a temporary HOME so tests don't touch the real database file.
@@ -18,6 +26,8 @@ func TestMain(m *testing.M) {
 	}
 	originalHome := os.Getenv("HOME")
 	_ = os.Setenv("HOME", tempDir)
+	_ = os.Unsetenv("XDG_CONFIG_HOME")
+	_ = os.Unsetenv("LGRT_DB")
 	code := m.Run()
 	if originalHome == "" {
 		_ = os.Unsetenv("HOME")
@@ -28,10 +38,15 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
-// resetDb resets the global database and id source.
+// resetDb resets the global database and id source and removes the database files.
 func resetDb() {
 	id.IdSource.SetLastId(0)
 	Db = NewDatabase()
+	if path, err := Path(); err == nil {
+		_ = os.Remove(path)
+		_ = os.Remove(path + ".journal")
+		_ = os.Remove(path + ".index")
+	}
 }
 
 // captureOutput captures stdout for the duration of fn.
@@ -78,7 +93,9 @@ func TestDatabaseSaveLoad(t *testing.T) {
 	item.Tags = []uint32{tagID}
 	Db.Items.Add(item)
 
-	Db.Save()
+	if err := Db.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
 
 	dbPath := dbFilePath(t)
 	if _, err := os.Stat(dbPath); err != nil {
@@ -102,6 +119,76 @@ func TestDatabaseSaveLoad(t *testing.T) {
 	}
 }
 
+// TestDatabaseSaveBackups verifies that Save rotates previous generations.
+func TestDatabaseSaveBackups(t *testing.T) {
+	resetDb()
+	oldGenerations := BackupGenerations
+	BackupGenerations = 2
+	defer func() { BackupGenerations = oldGenerations }()
+	dbPath := dbFilePath(t)
+	for n := 1; n <= 3; n++ {
+		_ = os.Remove(BackupPath(dbPath, n))
+	}
+
+	for _, name := range []string{"First", "Second", "Third", "Fourth"} {
+		Db.Warehouses.Add(NewDataset[Warehouse](name, Warehouse{}))
+		if err := Db.Save(); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	backup, err := os.ReadFile(BackupPath(dbPath, 1))
+	if err != nil {
+		t.Fatalf("expected newest backup: %v", err)
+	}
+	if !strings.Contains(string(backup), "Third") || strings.Contains(string(backup), "Fourth") {
+		t.Fatalf("newest backup should hold the previous state: %s", backup)
+	}
+	if _, err := os.Stat(BackupPath(dbPath, 2)); err != nil {
+		t.Fatalf("expected second backup: %v", err)
+	}
+	if _, err := os.Stat(BackupPath(dbPath, 3)); !os.IsNotExist(err) {
+		t.Fatalf("expected no third backup, got err=%v", err)
+	}
+	matches, _ := filepath.Glob(dbPath + ".tmp-*")
+	if len(matches) != 0 {
+		t.Fatalf("temp files left behind: %v", matches)
+	}
+}
+
+// TestDatabaseSaveError verifies that Save reports an unwritable data directory.
+func TestDatabaseSaveError(t *testing.T) {
+	resetDb()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".lgrt"), []byte("not a directory"), 0644); err != nil {
+		t.Fatalf("write blocker: %v", err)
+	}
+	if err := Db.Save(); err == nil {
+		t.Fatalf("expected Save to fail")
+	}
+}
+
+// TestDatabaseSetPath verifies saving and loading a custom database file.
+func TestDatabaseSetPath(t *testing.T) {
+	resetDb()
+	path := filepath.Join(t.TempDir(), "workshop", "inventory.json")
+	SetPath(path)
+	defer SetPath("")
+
+	Db.Warehouses.Add(NewDataset[Warehouse]("Workshop", Warehouse{}))
+	if err := Db.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected db file at %s: %v", path, err)
+	}
+	db2 := NewDatabase()
+	if err := db2.Load(); err != nil || len(db2.Warehouses) != 1 {
+		t.Fatalf("custom path not loaded: %v %+v", err, db2.Warehouses)
+	}
+}
+
 // TestDatabaseLoadMissingFile ensures Load is a no-op when no file exists.
 func TestDatabaseLoadMissingFile(t *testing.T) {
 	resetDb()
@@ -112,6 +199,84 @@ func TestDatabaseLoadMissingFile(t *testing.T) {
 	}
 }
 
+// TestDatabaseLoadCorrupted verifies the position reported for a damaged file.
+func TestDatabaseLoadCorrupted(t *testing.T) {
+	resetDb()
+	dbPath := dbFilePath(t)
+	_ = os.MkdirAll(filepath.Dir(dbPath), os.ModePerm)
+	if err := os.WriteFile(dbPath, []byte("{\n  \"warehouses\": [}\n"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	Db.Warehouses.Add(NewDataset[Warehouse]("Keep", Warehouse{}))
+
+	err := Db.Load()
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	if loadErr.Line != 2 || loadErr.Column != 18 {
+		t.Fatalf("unexpected position %d:%d", loadErr.Line, loadErr.Column)
+	}
+	if len(Db.Warehouses) != 1 {
+		t.Fatalf("database must stay untouched on a failed load")
+	}
+	_ = os.Remove(dbPath)
+}
+
+// TestNewestValidBackup verifies that damaged backups are skipped.
+func TestNewestValidBackup(t *testing.T) {
+	resetDb()
+	dbPath := dbFilePath(t)
+	_ = os.MkdirAll(filepath.Dir(dbPath), os.ModePerm)
+	_ = os.WriteFile(BackupPath(dbPath, 1), []byte("{\"warehouses\":["), 0644)
+	_ = os.WriteFile(BackupPath(dbPath, 2), []byte(`{"warehouses":[{"id":7,"name":"Old"}]}`), 0644)
+	defer func() {
+		_ = os.Remove(BackupPath(dbPath, 1))
+		_ = os.Remove(BackupPath(dbPath, 2))
+	}()
+
+	n, ok := NewestValidBackup()
+	if !ok || n != 2 {
+		t.Fatalf("expected backup 2, got %d (ok=%v)", n, ok)
+	}
+	if err := Db.LoadBackup(n); err != nil {
+		t.Fatalf("load backup: %v", err)
+	}
+	if len(Db.Warehouses) != 1 || Db.Warehouses[0].Name != "Old" {
+		t.Fatalf("backup not loaded: %+v", Db.Warehouses)
+	}
+}
+
+// TestSalvageTruncated verifies that intact tables and entries survive truncation.
+func TestSalvageTruncated(t *testing.T) {
+	content := `{"currentWarehouseid":1,` +
+		`"warehouses":[{"id":1,"name":"Home"}],` +
+		`"rooms":[{"id":2,"name":"Kitchen","data":{"warehouseId":1}},{"id":3,"name":7},{"id":4,"name":"Hall"},{"id":5,"na`
+
+	db, report := Salvage([]byte(content))
+	if db.CurrentWarehouse != 1 || len(db.Warehouses) != 1 {
+		t.Fatalf("expected intact tables to survive: %+v", db)
+	}
+	if len(db.Rooms) != 2 || db.Rooms[1].Name != "Hall" {
+		t.Fatalf("expected two salvaged rooms, got %+v", db.Rooms)
+	}
+	var rooms, items TableReport
+	for _, table := range report.Tables {
+		switch table.Name {
+		case "rooms":
+			rooms = table
+		case "items":
+			items = table
+		}
+	}
+	if rooms.Kept != 2 || rooms.Dropped != 1 || !rooms.Truncated {
+		t.Fatalf("unexpected rooms report: %+v", rooms)
+	}
+	if !items.Missing {
+		t.Fatalf("expected items to be reported missing: %+v", items)
+	}
+}
+
 // TestCountTagOccurance verifies counting tag usage across tables.
 func TestCountTagOccurance(t *testing.T) {
 	resetDb()
@@ -297,3 +462,474 @@ func TestDataTableAddEmptyName(t *testing.T) {
 		t.Fatalf("expected default name, got %q", dt[0].Name)
 	}
 }
+
+// TestDatabaseSaveConflict verifies optimistic revision checks on Save.
+func TestDatabaseSaveConflict(t *testing.T) {
+	resetDb()
+	if err := Db.Save(); err != nil {
+		t.Fatalf("initial save: %v", err)
+	}
+	first := NewDatabase()
+	second := NewDatabase()
+	if err := first.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := second.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := second.Save(); err != nil {
+		t.Fatalf("second save: %v", err)
+	}
+	if err := first.Save(); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	if err := second.Save(); err != nil {
+		t.Fatalf("repeated save by the same writer must succeed: %v", err)
+	}
+	if err := first.ForceSave(); err != nil {
+		t.Fatalf("force save: %v", err)
+	}
+	if first.Revision != 4 {
+		t.Fatalf("expected revision 4, got %d", first.Revision)
+	}
+}
+
+// TestDataTableIndex verifies that lookups follow renames, replaced entries, reassignments and duplicates.
+func TestDataTableIndex(t *testing.T) {
+	resetDb()
+	dt := &Db.Boxes
+	a := NewDataset[Box]("Alpha", Box{})
+	dt.Add(a)
+	b := NewDataset[Box]("alpha", Box{})
+	dt.Add(b)
+
+	if sets := dt.GetSetsByName("ALPHA"); len(sets) != 2 || sets[0].ID != a.ID {
+		t.Fatalf("unexpected sets by name: %+v", sets)
+	}
+
+	renamed := (*dt)[0]
+	renamed.Name = "Gamma"
+	dt.Replace(0, renamed)
+	if _, ok := dt.GetFirstOccurance("gamma"); !ok {
+		t.Fatalf("expected renamed entry to be found")
+	}
+	if got, _ := dt.GetFirstOccurance("alpha"); got != b.ID {
+		t.Fatalf("expected remaining alpha %d, got %d", b.ID, got)
+	}
+
+	// replacing an entry in place must not return its old id
+	other := NewDataset[Box]("Other", Box{})
+	dt.Replace(0, other)
+	if idx := dt.GetIdx(a.ID); idx != -1 {
+		t.Fatalf("expected replaced id to be gone, got %d", idx)
+	}
+	if idx := dt.GetIdx(other.ID); idx != 0 {
+		t.Fatalf("expected new id at index 0, got %d", idx)
+	}
+
+	// replacing the whole table must not return stale positions
+	*dt = DataTable[Box]{b}
+	if idx := dt.GetIdx(b.ID); idx != 0 {
+		t.Fatalf("expected index 0 after reassignment, got %d", idx)
+	}
+	if idx := dt.GetIdx(a.ID); idx != -1 {
+		t.Fatalf("expected missing id after reassignment, got %d", idx)
+	}
+
+	// duplicate ids resolve to the first live entry
+	dup := b
+	dup.Name = "Copy"
+	dt.Delete(b.ID)
+	dt.Add(dup)
+	if idx := dt.GetIdx(b.ID); idx != 1 {
+		t.Fatalf("expected live duplicate at index 1, got %d", idx)
+	}
+}
+
+// TestCheck verifies that Check reports orphans, dangling tags, duplicate ids and negative amounts.
+func TestCheck(t *testing.T) {
+	resetDb()
+	wh := NewDataset("WH", Warehouse{})
+	Db.Warehouses.Add(wh)
+	room := NewDataset("Room", Room{WarehouseId: wh.ID})
+	Db.Rooms.Add(room)
+	if problems := Db.Check(); len(problems) != 0 {
+		t.Fatalf("expected no problems, got %+v", problems)
+	}
+
+	Db.Warehouses.Delete(wh.ID)
+	item := NewDataset("Drill", Item{BoxId: 999, Amount: -1})
+	item.Tags = []uint32{998}
+	Db.Items.Add(item)
+	Db.Categories.Add(Dataset[Category]{ID: item.ID, Name: "Clash"})
+
+	kinds := map[string]int{}
+	for _, p := range Db.Check() {
+		kinds[p.Kind]++
+	}
+	want := map[string]int{ProblemOrphan: 2, ProblemDanglingTag: 1, ProblemDuplicateId: 1, ProblemNegativeAmount: 1}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("expected %v, got %v", want, kinds)
+	}
+}
+
+// TestLoadSchemaFixtures verifies that files of every historic schema version load and migrate.
+func TestLoadSchemaFixtures(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "schema*.json"))
+	if err != nil || len(fixtures) != SchemaVersion+1 {
+		t.Fatalf("expected a fixture per schema version, got %v (%v)", fixtures, err)
+	}
+	for version, fixture := range fixtures {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			content, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatalf("read fixture: %v", err)
+			}
+			path := filepath.Join(t.TempDir(), "lgrtdata.json")
+			if err := os.WriteFile(path, content, 0644); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			SetPath(path)
+			defer SetPath("")
+			Db = NewDatabase()
+			if err := Db.Load(); err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if Db.SchemaVersion != SchemaVersion || Db.CurrentWarehouse != 1 {
+				t.Fatalf("unexpected database header: %+v", Db)
+			}
+			if len(Db.Items) != 2 || Db.Items[0].Data.Amount != 1 || Db.Items[0].Data.BoxId != 4 || GetTagList(Db.Items[0].Tags) != "lent" {
+				t.Fatalf("unexpected items: %+v", Db.Items)
+			}
+			if Db.Items[0].Description != "cordless\nwith charger" || Db.Boxes[0].Data.Type != "crate" {
+				t.Fatalf("unexpected field values: %+v %+v", Db.Items[0], Db.Boxes[0])
+			}
+			if !Db.Items[1].Deleted || Db.Items[1].DeletedAt != 1700100000 {
+				t.Fatalf("expected deletion time, got %+v", Db.Items[1])
+			}
+			drill := Db.Items[0].Data
+			if version >= 2 && (len(drill.Ledger) != 1 || drill.Ledger[0].Reason != "bought") {
+				t.Fatalf("expected ledger, got %+v", drill.Ledger)
+			}
+			if version >= 3 && (drill.Loan == nil || drill.Loan.Borrower != "Anna") {
+				t.Fatalf("expected loan, got %+v", drill.Loan)
+			}
+			if version >= 4 && drill.MinAmount != 1 {
+				t.Fatalf("expected minimum amount, got %+v", drill)
+			}
+			if version >= 5 && drill.Expires.ISO() != "2030-01-31" {
+				t.Fatalf("expected expiry date, got %+v", drill)
+			}
+			if version >= 6 && (drill.UnitPrice != 8999 || drill.Vendor != "Hardware Store" || drill.Serial != "SN-4711") {
+				t.Fatalf("expected purchase details, got %+v", drill)
+			}
+			if version >= 7 && (drill.WarrantyUntil.ISO() != "2025-11-14" || drill.Receipt != "receipts/drill.pdf") {
+				t.Fatalf("expected warranty details, got %+v", drill)
+			}
+			if version >= 8 && Db.Views["tools"] != `category:"Power tools"` {
+				t.Fatalf("expected saved view, got %+v", Db.Views)
+			}
+			backup, err := os.ReadFile(SchemaBackupPath(path, version))
+			if version < SchemaVersion && string(backup) != string(content) {
+				t.Fatalf("expected unmigrated backup, got %q (%v)", backup, err)
+			}
+			if version == SchemaVersion && err == nil {
+				t.Fatal("expected no backup for a current file")
+			}
+
+			if err := Db.Save(); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			saved, _ := os.ReadFile(path)
+			if !strings.Contains(string(saved), fmt.Sprintf(`"schemaVersion":%d`, SchemaVersion)) {
+				t.Fatalf("expected schema version in saved file, got %s", saved)
+			}
+		})
+	}
+}
+
+// TestLoadNewerSchema verifies that files of an unknown schema version are refused.
+func TestLoadNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lgrtdata.json")
+	if err := os.WriteFile(path, []byte(`{"schemaVersion":99,"items":[]}`), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	SetPath(path)
+	defer SetPath("")
+	Db = NewDatabase()
+	err := Db.Load()
+	var loadErr *LoadError
+	if !errors.Is(err, ErrNewerSchema) || errors.As(err, &loadErr) {
+		t.Fatalf("expected ErrNewerSchema, got %v", err)
+	}
+}
+
+// TestOutputFormats verifies the machine-readable list output.
+func TestOutputFormats(t *testing.T) {
+	resetDb()
+	defer func() { Output = OutputText }()
+	wh := NewDataset("Home", Warehouse{})
+	Db.Warehouses.Add(wh)
+	room := NewDataset("Basement", Room{WarehouseId: wh.ID})
+	Db.Rooms.Add(room)
+	shelf := NewDataset("Rack", Shelf{RoomId: room.ID})
+	Db.Shelves.Add(shelf)
+	box := NewDataset("Tools", Box{ShelfId: shelf.ID})
+	Db.Boxes.Add(box)
+	item := NewDataset("Drill, cordless", Item{BoxId: box.ID, Amount: 2})
+	item.Tags = GetTagIds("blue, heavy")
+	Db.Items.Add(item)
+
+	Output = OutputJSON
+	out := captureOutput(t, func() { Db.Items.PrintList(false) })
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if len(rows) != 1 || rows[0]["box"] != "Tools" || rows[0]["warehouse"] != "Home" || rows[0]["amount"] != 2.0 {
+		t.Fatalf("unexpected JSON rows: %v", rows)
+	}
+
+	Output = OutputCSV
+	out = captureOutput(t, func() { Db.Items.PrintList(false) })
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("invalid CSV %q: %v", out, err)
+	}
+	if records[1][1] != "Drill, cordless" || records[1][5] != "blue, heavy" || strings.Contains(out, "\033") {
+		t.Fatalf("unexpected CSV row: %v", records[1])
+	}
+
+	Output = OutputTSV
+	out = captureOutput(t, func() { Db.FindItem("nothing", false) })
+	if !strings.HasPrefix(out, "id\tname\t") || strings.Count(out, "\n") != 1 {
+		t.Fatalf("expected header only, got %q", out)
+	}
+}
+
+// TestDate verifies parsing, formatting and the JSON form of dates.
+func TestDate(t *testing.T) {
+	d, err := ParseDate("31.05.2024")
+	if err != nil || d.ISO() != "2024-05-31" || d.String() != "31.05.2024" {
+		t.Fatalf("unexpected date %q: %v", d.ISO(), err)
+	}
+	if _, err := ParseDate("2024-02-30"); err == nil {
+		t.Fatal("expected invalid date to be rejected")
+	}
+	if d.AddDays(1).ISO() != "2024-06-01" || Today().AddDays(3).DaysUntil() != 3 {
+		t.Fatal("unexpected date arithmetic")
+	}
+	for _, days := range []int{-1, -3, -40} {
+		if got := Today().AddDays(days).DaysUntil(); got != days {
+			t.Fatalf("expected %d days until a past date, got %d", days, got)
+		}
+	}
+
+	content, err := json.Marshal(Item{Expires: d})
+	if err != nil || !strings.Contains(string(content), `"expires":"2024-05-31"`) {
+		t.Fatalf("unexpected JSON %s: %v", content, err)
+	}
+	var item Item
+	if err := json.Unmarshal(content, &item); err != nil || item.Expires != d {
+		t.Fatalf("round trip failed: %v", err)
+	}
+	if content, _ := json.Marshal(Item{}); strings.Contains(string(content), "expires") {
+		t.Fatalf("expected no expiry date in %s", content)
+	}
+}
+
+// TestMoney verifies price parsing and the sums per currency.
+func TestMoney(t *testing.T) {
+	for text, want := range map[string]Money{"12.99": 1299, "12,9": 1290, "7": 700, "": 0} {
+		if got, err := ParseMoney(text); err != nil || got != want {
+			t.Fatalf("ParseMoney(%q) = %d, %v, want %d", text, got, err, want)
+		}
+	}
+	for _, text := range []string{"1.999", "-3", "abc", ".5"} {
+		if _, err := ParseMoney(text); err == nil {
+			t.Fatalf("expected %q to be rejected", text)
+		}
+	}
+	totals := Totals{}
+	totals.Add(Item{Amount: 3, UnitPrice: 250}.Value(), "eur")
+	totals.Add(1000, "USD")
+	totals.Add(5, "EUR")
+	if totals.String() != "7.55 EUR + 10.00 USD" {
+		t.Fatalf("unexpected totals %q", totals.String())
+	}
+}
+
+// TestParseQuery verifies fields, comparisons, boolean operators and query errors.
+func TestParseQuery(t *testing.T) {
+	resetDb()
+	wh := NewDataset("Home", Warehouse{})
+	Db.Warehouses.Add(wh)
+	room := NewDataset("Basement", Room{WarehouseId: wh.ID})
+	Db.Rooms.Add(room)
+	shelf := NewDataset("Rack", Shelf{RoomId: room.ID})
+	Db.Shelves.Add(shelf)
+	box := NewDataset("Tools", Box{ShelfId: shelf.ID})
+	Db.Boxes.Add(box)
+	cat := Db.Categories.AddSimple("Power tools")
+	drill := NewDataset("Drill", Item{BoxId: box.ID, CategoryId: cat, Amount: 1})
+	drill.Description = "cordless, stainless steel chuck"
+	drill.Tags = GetTagIds("broken")
+	Db.Items.Add(drill)
+	saw := NewDataset("Saw", Item{BoxId: box.ID, Amount: 5, Loan: &Loan{Borrower: "Anna"}})
+	saw.Updated = time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local).Unix()
+	Db.Items.Add(saw)
+	Db.Items.Add(NewDataset("Hammer", Item{Amount: 2}))
+	Db.Items.Add(NewDataset("Schraubenzieher", Item{Amount: 3}))
+
+	for query, want := range map[string]string{
+		"drill":                                 "Drill",
+		"rill":                                  "Drill",
+		"zieher":                                "Schraubenzieher",
+		"name:drill tag:broken cat:tools":       "Drill",
+		"room:Basement amount<2":                "Drill",
+		`"stainless steel"`:                     "Drill",
+		"desc:\"steel chuck\"":                  "Drill",
+		"room:basement -tag:broken":             "Saw",
+		"updated<2026-01-01":                    "Saw",
+		"lent:anna OR name=hammer":              "Hammer,Saw",
+		"NOT (box:tools AND amount>=5)":         "Drill,Hammer,Schraubenzieher",
+		"amount>1 AND (name:saw OR name:drill)": "Saw",
+		"cat=tools":                             "",
+	} {
+		filter, err := ParseQuery(query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q): %v", query, err)
+		}
+		var names []string
+		for _, set := range Db.Items {
+			if filter(set) {
+				names = append(names, set.Name)
+			}
+		}
+		slices.Sort(names)
+		if got := strings.Join(names, ","); got != want {
+			t.Fatalf("ParseQuery(%q) matched %q, want %q", query, got, want)
+		}
+	}
+
+	for query, want := range map[string]string{
+		"":                  "empty query",
+		"(drill":            "missing \")\" for the \"(\" at position 1",
+		"drill)":            "unexpected \")\" at position 6",
+		"drill OR":          "OR at position 7 needs a term after it",
+		"AND drill":         "AND at position 1 needs a term before it",
+		"colour:red":        "unknown field \"colour\" at position 1",
+		"amount<lots":       "amount at position 1 needs a number",
+		"name>a":            "cannot be compared with >",
+		"updated>yesterday": "invalid date",
+		"\"open":            "missing closing quote",
+		"tag:":              "missing value after \"tag:\"",
+	} {
+		if _, err := ParseQuery(query); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("ParseQuery(%q) error %v, want %q", query, err, want)
+		}
+	}
+}
+
+// TestFuzzyFind verifies typo tolerance, ranking and the highlighted match.
+func TestFuzzyFind(t *testing.T) {
+	resetDb()
+	cat := Db.Categories.AddSimple("Hand tools")
+	Db.Items.Add(NewDataset("Screwdriver set", Item{CategoryId: cat}))
+	bits := NewDataset("Bits", Item{Location: "drawer"})
+	bits.Description = "for the cordless screwdriver"
+	Db.Items.Add(bits)
+	Db.Items.Add(NewDataset("Hammer", Item{CategoryId: cat}))
+
+	results := Db.FuzzyFind("screwdriwer")
+	if len(results) != 2 || results[0].Set.Name != "Screwdriver set" || results[1].Set.Name != "Bits" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if results[0].Score <= results[1].Score || results[1].Field != "description" {
+		t.Fatalf("expected the name match first, got %+v", results)
+	}
+	want := "for the cordless " + terminal.GetHighlightText("screwdriver")
+	if got := results[1].Highlight(); got != want {
+		t.Fatalf("Highlight() = %q, want %q", got, want)
+	}
+	if results := Db.FuzzyFind("hamer tols"); len(results) != 1 || results[0].Set.Name != "Hammer" {
+		t.Fatalf("expected every word to match, got %+v", results)
+	}
+	if results := Db.FuzzyFind("saw"); len(results) != 0 {
+		t.Fatalf("expected no match, got %+v", results)
+	}
+}
+
+// TestSearchIndex verifies stemming, substring lookups and keeping the index current.
+func TestSearchIndex(t *testing.T) {
+	for word, want := range map[string]string{"schrauben": "schraub", "schraube": "schraub", "screwdrivers": "screwdriv",
+		"batteries": "battery", "säge": "sag", "saege": "sag", "drilling": "drill", "box": "box"} {
+		if got := Stem(word); got != want {
+			t.Fatalf("Stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+
+	resetDb()
+	screws := NewDataset("Schrauben M6", Item{Location: "Regal links"})
+	Db.Items.Add(screws)
+	saw := NewDataset("Säge", Item{})
+	saw.Description = "for cutting boxes"
+	Db.Items.Add(saw)
+	for query, want := range map[string][]uint32{
+		"schraube":   {screws.ID},
+		"schr m6":    {screws.ID},
+		"regal":      {screws.ID},
+		"saege":      {saw.ID},
+		"rauben":     {screws.ID},
+		"box cut":    {saw.ID},
+		"box schrau": nil,
+	} {
+		var got []uint32
+		for id := range Db.SearchIndex().Lookup(query) {
+			got = append(got, id)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("Lookup(%q) = %v, want %v", query, got, want)
+		}
+	}
+
+	// a saved item is indexed on the next search and the index is written
+	if err := Db.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	drill := NewDataset("Drill", Item{})
+	Db.Items.Add(drill)
+	if err := Db.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if !Db.SearchIndex().Lookup("rill")[drill.ID] {
+		t.Fatal("expected the new item to be indexed")
+	}
+	if ix := readSearchIndex(); ix == nil || ix.Revision != Db.Revision || !ix.Lookup("drill")[drill.ID] {
+		t.Fatal("expected the index to be stored")
+	}
+
+	// after a save the changed records are indexed again
+	if err := Db.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	set, _ := Db.Items.GetPtr(drill.ID)
+	set.Name = "Hammer drill"
+	if err := Db.UpdateIndex([]Ref{{"items", drill.ID}}); err != nil {
+		t.Fatalf("update index: %v", err)
+	}
+	if ix := readSearchIndex(); ix.Revision != Db.Revision || !ix.Lookup("hammer")[drill.ID] {
+		t.Fatal("expected the changed item to be indexed")
+	}
+
+	// an index of another revision is rebuilt
+	Db.Revision++
+	Db.Items.Delete(screws.ID)
+	if ix := Db.SearchIndex(); ix.Revision != Db.Revision || len(ix.Lookup("schraube")) != 0 {
+		t.Fatal("expected the stale index to be rebuilt")
+	}
+	// but only written for the revision of the file
+	if ix := readSearchIndex(); ix.Revision == Db.Revision {
+		t.Fatal("expected no index for a revision that was not saved")
+	}
+}
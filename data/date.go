@@ -0,0 +1,93 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/elsni/lagerator/terminal"
+)
+
+// Date is a calendar day stored as the Unix time of its local midnight, 0 means no date.
+// It is written as YYYY-MM-DD in the database.
+type Date int64
+
+// dateLayouts are the accepted input formats, the first is used in files.
+var dateLayouts = []string{time.DateOnly, "02.01.2006", "2.1.2006"}
+
+// ParseDate parses a date like 2024-05-31 or 31.05.2024. An empty string is no date.
+func ParseDate(text string) (Date, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			return DateOf(t), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid date \"%s\", use YYYY-MM-DD or DD.MM.YYYY", text)
+}
+
+// DateOf returns the day of t.
+func DateOf(t time.Time) Date {
+	return Date(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local).Unix())
+}
+
+// Today returns the current day.
+func Today() Date {
+	return DateOf(time.Now())
+}
+
+// Time returns the start of the day.
+func (d Date) Time() time.Time {
+	return time.Unix(int64(d), 0)
+}
+
+// AddDays returns the day n days later.
+func (d Date) AddDays(n int) Date {
+	return DateOf(d.Time().AddDate(0, 0, n))
+}
+
+// DaysUntil returns the number of days from today to d, negative if d has passed.
+// Adding half a day before rounding down absorbs daylight saving shifts.
+func (d Date) DaysUntil() int {
+	return int(math.Floor((d.Time().Sub(Today().Time()).Hours() + 12) / 24))
+}
+
+// String formats the date like terminal.GetDateString, "" for no date.
+func (d Date) String() string {
+	if d == 0 {
+		return ""
+	}
+	return terminal.GetDateString(int64(d))
+}
+
+// ISO formats the date as YYYY-MM-DD, "" for no date.
+func (d Date) ISO() string {
+	if d == 0 {
+		return ""
+	}
+	return d.Time().Format(time.DateOnly)
+}
+
+// MarshalJSON writes the date as YYYY-MM-DD.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.ISO())
+}
+
+// UnmarshalJSON reads a date written by MarshalJSON.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var text string
+	if err := json.Unmarshal(b, &text); err != nil {
+		return err
+	}
+	parsed, err := ParseDate(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
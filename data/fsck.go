@@ -0,0 +1,127 @@
+package data
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Kinds of problems found by Check.
+const (
+	ProblemOrphan         = "orphan"
+	ProblemDanglingRef    = "dangling reference"
+	ProblemDanglingTag    = "dangling tag"
+	ProblemDuplicateId    = "duplicate id"
+	ProblemNegativeAmount = "negative amount"
+)
+
+// Problem is an integrity violation of a live record.
+type Problem struct {
+	Kind string
+	RecordInfo
+	Detail string
+	// Parent is the missing container or category, TagId the missing tag
+	Parent Ref
+	TagId  uint32
+}
+
+// checker holds the state of one Check run.
+type checker struct {
+	ids map[uint32][]RecordInfo
+	// records maps every record to whether it is live
+	records  map[Ref]bool
+	problems []Problem
+}
+
+// Check validates the references between the tables: every live record must
+// sit in a live container, items may only refer to live categories, tags
+// must exist, ids must be unique across all tables and amounts must not be
+// negative. A container always belongs to the table above, so containment
+// can't form cycles.
+func (db *Database) Check() []Problem {
+	c := &checker{ids: map[uint32][]RecordInfo{}, records: map[Ref]bool{}}
+	collect(c, db, &db.Warehouses)
+	collect(c, db, &db.Rooms)
+	collect(c, db, &db.Shelves)
+	collect(c, db, &db.Boxes)
+	collect(c, db, &db.Items)
+	collect(c, db, &db.Categories)
+	collect(c, db, &db.Tags)
+	checkTable(c, db, &db.Warehouses)
+	checkTable(c, db, &db.Rooms)
+	checkTable(c, db, &db.Shelves)
+	checkTable(c, db, &db.Boxes)
+	checkTable(c, db, &db.Items)
+	checkTable(c, db, &db.Categories)
+	checkTable(c, db, &db.Tags)
+	var dups []uint32
+	for id, infos := range c.ids {
+		if len(infos) > 1 {
+			dups = append(dups, id)
+		}
+	}
+	slices.Sort(dups)
+	for _, id := range dups {
+		// the first record keeps the id, every other one is reported
+		first := c.ids[id][0]
+		for _, info := range c.ids[id][1:] {
+			c.problems = append(c.problems, Problem{Kind: ProblemDuplicateId, RecordInfo: info,
+				Detail: fmt.Sprintf("id is also used by %s \"%s\"", strings.ToLower(first.Type), first.Name)})
+		}
+	}
+	return c.problems
+}
+
+// collect records the ids and state of the records of a table.
+func collect[T CustomData](c *checker, db *Database, dt *DataTable[T]) {
+	table := db.TableName(dt)
+	for i, set := range *dt {
+		info := dt.info(i, table)
+		c.ids[set.ID] = append(c.ids[set.ID], info)
+		// a live record wins over a deleted one with the same id
+		c.records[info.Ref] = c.records[info.Ref] || !set.Deleted
+	}
+}
+
+// checkTable checks the live records of one table.
+func checkTable[T CustomData](c *checker, db *Database, dt *DataTable[T]) {
+	table := db.TableName(dt)
+	for i, set := range *dt {
+		if set.Deleted {
+			continue
+		}
+		info := dt.info(i, table)
+		if container, ok := containerRef(set.Data); ok && !c.records[container] {
+			c.problems = append(c.problems, Problem{Kind: ProblemOrphan, RecordInfo: info, Parent: container,
+				Detail: fmt.Sprintf("%s %d %s", container.Table, container.ID, c.describeMissing(container))})
+		}
+		if item, ok := any(set.Data).(Item); ok {
+			category := Ref{"categories", item.CategoryId}
+			if item.CategoryId != 0 && !c.records[category] {
+				c.problems = append(c.problems, Problem{Kind: ProblemDanglingRef, RecordInfo: info, Parent: category,
+					Detail: fmt.Sprintf("category %d %s", item.CategoryId, c.describeMissing(category))})
+			}
+			if item.Amount < 0 {
+				c.problems = append(c.problems, Problem{Kind: ProblemNegativeAmount, RecordInfo: info, Detail: fmt.Sprintf("amount is %d", item.Amount)})
+			}
+		}
+		for _, tagid := range set.Tags {
+			tag := Ref{"tags", tagid}
+			if !c.records[tag] {
+				c.problems = append(c.problems, Problem{Kind: ProblemDanglingTag, RecordInfo: info, TagId: tagid,
+					Detail: fmt.Sprintf("tag %d %s", tagid, c.describeMissing(tag))})
+			}
+		}
+	}
+}
+
+// describeMissing explains why a referenced record is not usable.
+func (c *checker) describeMissing(ref Ref) string {
+	if ref.ID == 0 {
+		return "is not set"
+	}
+	if _, ok := c.records[ref]; ok {
+		return "is deleted"
+	}
+	return "does not exist"
+}
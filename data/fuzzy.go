@@ -0,0 +1,250 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/elsni/lagerator/terminal"
+)
+
+// fuzzyThreshold is the lowest similarity at which a word counts as a match.
+const fuzzyThreshold = 0.7
+
+// fuzzySnippet is the number of characters shown around a match.
+const fuzzySnippet = 50
+
+// FuzzyResult is an item found by a fuzzy search.
+type FuzzyResult struct {
+	Set   Dataset[Item]
+	Score float64
+	Field string // the field shown as the match
+	Text  string // the text of that field
+	Spans []span // the matched words in Text
+}
+
+// span is a range of runes.
+type span struct{ start, end int }
+
+// fuzzyField is a searchable text of an item with its weight.
+type fuzzyField struct {
+	name   string
+	weight float64
+	text   string
+}
+
+// fuzzyFields returns the texts of an item the fuzzy search looks at.
+func fuzzyFields(set Dataset[Item]) []fuzzyField {
+	return []fuzzyField{
+		{"name", 1, set.Name},
+		{"tags", 0.9, GetTagList(set.Tags)},
+		{"category", 0.9, nameOf(Ref{"categories", set.Data.CategoryId})},
+		{"location", 0.8, set.Data.Location},
+		{"description", 0.7, set.Description},
+	}
+}
+
+// words splits a text into lowercase words and their rune ranges.
+func words(text string) ([]string, []span) {
+	var list []string
+	var spans []span
+	runes := []rune(text)
+	start := -1
+	for i := 0; i <= len(runes); i++ {
+		if i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			list = append(list, strings.ToLower(string(runes[start:i])))
+			spans = append(spans, span{start, i})
+			start = -1
+		}
+	}
+	return list, spans
+}
+
+// similarity rates how well a query word matches a word, from 0 to 1.
+func similarity(query, word string) float64 {
+	switch {
+	case query == word:
+		return 1
+	case strings.HasPrefix(word, query):
+		return 0.9
+	case strings.Contains(word, query):
+		return 0.8
+	}
+	q, w := []rune(query), []rune(word)
+	edits := 1 - float64(levenshtein(q, w))/float64(max(len(q), len(w)))
+	return max(edits, trigramSimilarity(query, word))
+}
+
+// levenshtein returns the number of edits that turn a into b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// trigramSimilarity returns the share of trigrams two words have in common.
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	common := 0
+	for t := range ta {
+		if tb[t] {
+			common++
+		}
+	}
+	if all := len(ta) + len(tb) - common; all > 0 {
+		return float64(common) / float64(all)
+	}
+	return 0
+}
+
+// trigrams returns the trigrams of a word padded with spaces.
+func trigrams(word string) map[string]bool {
+	runes := []rune("  " + word + " ")
+	set := map[string]bool{}
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
+
+// FuzzyFind returns the live items in the Scope matching every word of the
+// query, tolerating typos, the best matches first.
+func (db *Database) FuzzyFind(query string) []FuzzyResult {
+	queryWords, _ := words(query)
+	var results []FuzzyResult
+	if len(queryWords) == 0 {
+		return results
+	}
+	for _, set := range db.Items {
+		if set.Deleted || !InScope(set) {
+			continue
+		}
+		if result, ok := fuzzyMatch(set, queryWords); ok {
+			results = append(results, result)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return strings.ToUpper(results[i].Set.Name) < strings.ToUpper(results[j].Set.Name)
+	})
+	return results
+}
+
+// fuzzyMatch scores an item. Every query word must match a word of one of
+// its fields, the score is the average of the best weighted matches.
+func fuzzyMatch(set Dataset[Item], queryWords []string) (FuzzyResult, bool) {
+	fields := fuzzyFields(set)
+	fieldWords := make([][]string, len(fields))
+	fieldSpans := make([][]span, len(fields))
+	for i, field := range fields {
+		fieldWords[i], fieldSpans[i] = words(field.text)
+	}
+	total, best, bestField := 0.0, 0.0, 0
+	for _, q := range queryWords {
+		wordBest := 0.0
+		for i, field := range fields {
+			for _, w := range fieldWords[i] {
+				if sim := similarity(q, w); sim >= fuzzyThreshold && sim*field.weight > wordBest {
+					wordBest = sim * field.weight
+					if wordBest > best {
+						best, bestField = wordBest, i
+					}
+				}
+			}
+		}
+		if wordBest == 0 {
+			return FuzzyResult{}, false
+		}
+		total += wordBest
+	}
+	result := FuzzyResult{Set: set, Score: total / float64(len(queryWords)), Field: fields[bestField].name, Text: fields[bestField].text}
+	for j, w := range fieldWords[bestField] {
+		for _, q := range queryWords {
+			if similarity(q, w) >= fuzzyThreshold {
+				result.Spans = append(result.Spans, fieldSpans[bestField][j])
+				break
+			}
+		}
+	}
+	return result, true
+}
+
+// Highlight returns the matched text around the first match with the matched
+// words in colour.
+func (r FuzzyResult) Highlight() string {
+	runes := []rune(r.Text)
+	from, to := 0, len(runes)
+	if len(r.Spans) > 0 && len(runes) > fuzzySnippet {
+		from = max(0, r.Spans[0].start-fuzzySnippet/4)
+		to = min(len(runes), from+fuzzySnippet)
+	}
+	var sb strings.Builder
+	if from > 0 {
+		sb.WriteString("…")
+	}
+	pos := from
+	for _, s := range r.Spans {
+		if s.start < pos || s.end > to {
+			continue
+		}
+		sb.WriteString(string(runes[pos:s.start]))
+		sb.WriteString(terminal.GetHighlightText(string(runes[s.start:s.end])))
+		pos = s.end
+	}
+	sb.WriteString(string(runes[pos:to]))
+	if to < len(runes) {
+		sb.WriteString("…")
+	}
+	return strings.ReplaceAll(sb.String(), "\n", " ")
+}
+
+// FindItemFuzzy prints the items matching a query with typos tolerated, ranked
+// by relevance or sorted by name.
+func (db *Database) FindItemFuzzy(query string, sortname bool) {
+	results := db.FuzzyFind(query)
+	if sortname {
+		sort.SliceStable(results, func(i, j int) bool {
+			return strings.ToUpper(results[i].Set.Name) < strings.ToUpper(results[j].Set.Name)
+		})
+	}
+	if Output != OutputText {
+		sets := []Dataset[Item]{}
+		for _, result := range results {
+			sets = append(sets, result.Set)
+		}
+		WriteSets(sets)
+		return
+	}
+	if len(results) == 0 {
+		fmt.Printf("Nothing found for \"%s\"\n", query)
+		return
+	}
+	fmt.Println(terminal.GetHeadlineText(" Item "))
+	fmt.Printf("%s%5s %5s %-30s %s%s\n", terminal.SetBgColor(terminal.COLORBLUE), "Score", "ID", "Name", "Match", terminal.ResetColor())
+	for _, result := range results {
+		fmt.Printf("%4.0f%% %5d %-30s %s: %s\n", result.Score*100, result.Set.ID, result.Set.GetPrintName(30), result.Field, result.Highlight())
+	}
+}
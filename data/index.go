@@ -0,0 +1,119 @@
+package data
+
+import "strings"
+
+// tableIndex maps ids and lower-cased names to positions in a DataTable.
+type tableIndex[T CustomData] struct {
+	ids   map[uint32]int
+	names map[string][]int
+	// dups is set when an id occurs more than once, lookups then fall back to a scan
+	dups bool
+	// n and first describe the table state the index was built for
+	n     int
+	first *Dataset[T]
+}
+
+// owns reports whether tbl points to one of the tables of the database.
+func (db *Database) owns(tbl any) bool {
+	switch tbl {
+	case &db.Warehouses, &db.Rooms, &db.Shelves, &db.Boxes, &db.Items, &db.Categories, &db.Tags:
+		return true
+	}
+	return false
+}
+
+// valid reports whether the index still describes the table. Changes made
+// through the table methods drop the index, this only notices a table that
+// was assigned as a whole.
+func (ix *tableIndex[T]) valid(dt *DataTable[T]) bool {
+	if len(*dt) != ix.n {
+		return false
+	}
+	return ix.n == 0 || &(*dt)[0] == ix.first
+}
+
+// add indexes the dataset at position i.
+func (ix *tableIndex[T]) add(dt *DataTable[T], i int) {
+	set := &(*dt)[i]
+	if _, ok := ix.ids[set.ID]; ok {
+		ix.dups = true
+	} else {
+		ix.ids[set.ID] = i
+	}
+	key := strings.ToLower(set.Name)
+	ix.names[key] = append(ix.names[key], i)
+	ix.n = len(*dt)
+	ix.first = &(*dt)[0]
+}
+
+// removeName drops position i from the name index.
+func (ix *tableIndex[T]) removeName(name string, i int) {
+	key := strings.ToLower(name)
+	list := ix.names[key]
+	for j, pos := range list {
+		if pos == i {
+			list = append(list[:j:j], list[j+1:]...)
+			break
+		}
+	}
+	if len(list) == 0 {
+		delete(ix.names, key)
+	} else {
+		ix.names[key] = list
+	}
+}
+
+// index returns the index of the table. The indexes of the tables of Db are
+// kept on it until the table changes or the database is loaded again, other
+// tables are indexed on every call.
+func (dt *DataTable[T]) index() *tableIndex[T] {
+	if ix := dt.kept(); ix != nil {
+		return ix
+	}
+	ix := &tableIndex[T]{
+		ids:   make(map[uint32]int, len(*dt)),
+		names: make(map[string][]int, len(*dt)),
+	}
+	for i := range *dt {
+		ix.add(dt, i)
+	}
+	if Db != nil && Db.owns(dt) {
+		if Db.indexes == nil {
+			Db.indexes = map[any]any{}
+		}
+		Db.indexes[dt] = ix
+	}
+	return ix
+}
+
+// kept returns the index Db keeps for the table, nil if there is none.
+func (dt *DataTable[T]) kept() *tableIndex[T] {
+	if Db == nil {
+		return nil
+	}
+	if ix, _ := Db.indexes[dt].(*tableIndex[T]); ix != nil && ix.valid(dt) {
+		return ix
+	}
+	return nil
+}
+
+// dropIndex discards the index of the table, the next lookup rebuilds it.
+func (dt *DataTable[T]) dropIndex() {
+	if Db != nil {
+		delete(Db.indexes, dt)
+	}
+}
+
+// appendIndexed appends a dataset and adds it to a kept index.
+func (dt *DataTable[T]) appendIndexed(set Dataset[T]) {
+	ix := dt.kept()
+	*dt = append(*dt, set)
+	if ix != nil {
+		ix.add(dt, len(*dt)-1)
+	}
+}
+
+// nameCandidates returns the positions whose name folds to name.
+func (dt *DataTable[T]) nameCandidates(name string) []int {
+	return dt.index().names[strings.ToLower(name)]
+}
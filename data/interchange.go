@@ -0,0 +1,123 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// ExportFormat identifies export documents, ExportVersion is their layout version.
+const (
+	ExportFormat  = "lagerator-export"
+	ExportVersion = 1
+)
+
+// Export is a self-contained part of an inventory: warehouses with their
+// rooms, shelves, boxes and items plus the categories and tags they use.
+// Ids are those of the exporting database, they are remapped on import.
+type Export struct {
+	Format     string         `json:"format"`
+	Version    int            `json:"version"`
+	Exported   int64          `json:"exported"`
+	Warehouses WarehouseTable `json:"warehouses"`
+	Rooms      RoomTable      `json:"rooms"`
+	Shelves    ShelfTable     `json:"shelves"`
+	Boxes      BoxTable       `json:"boxes"`
+	Items      ItemTable      `json:"items"`
+	Categories CategoryTable  `json:"categories"`
+	Tags       TagTable       `json:"tags"`
+}
+
+// Container returns the container of the dataset in the hierarchy.
+// ok is false for types that are not stored in a container.
+func (d Dataset[T]) Container() (ref Ref, ok bool) {
+	return containerRef(d.Data)
+}
+
+// References returns the records the dataset refers to: its container and,
+// for items, the category.
+func (d Dataset[T]) References() []Ref {
+	return parentRefs(d.Data)
+}
+
+// Export collects the live warehouses with the given ids and everything
+// they contain. If roomId is set only that room of its warehouse is included.
+func (db *Database) Export(warehouseIds []uint32, roomId uint32) *Export {
+	e := &Export{Format: ExportFormat, Version: ExportVersion, Exported: time.Now().Unix()}
+	included := map[Ref]bool{}
+	tagids := map[uint32]bool{}
+	keep := func(ref Ref, tags []uint32) {
+		included[ref] = true
+		for _, id := range tags {
+			tagids[id] = true
+		}
+	}
+	for _, set := range db.Warehouses {
+		if !set.Deleted && slices.Contains(warehouseIds, set.ID) {
+			e.Warehouses = append(e.Warehouses, set)
+			keep(Ref{"warehouses", set.ID}, set.Tags)
+		}
+	}
+	e.Rooms = exportChildren(db.Rooms, "rooms", included, keep, func(set Dataset[Room]) bool {
+		return roomId == 0 || set.ID == roomId
+	})
+	e.Shelves = exportChildren(db.Shelves, "shelves", included, keep, nil)
+	e.Boxes = exportChildren(db.Boxes, "boxes", included, keep, nil)
+	e.Items = exportChildren(db.Items, "items", included, keep, nil)
+	for _, set := range db.Categories {
+		if !set.Deleted && slices.ContainsFunc(e.Items, func(item Dataset[Item]) bool { return item.Data.CategoryId == set.ID }) {
+			e.Categories = append(e.Categories, set)
+			keep(Ref{"categories", set.ID}, set.Tags)
+		}
+	}
+	for _, set := range db.Tags {
+		if !set.Deleted && tagids[set.ID] {
+			e.Tags = append(e.Tags, set)
+		}
+	}
+	return e
+}
+
+// exportChildren returns the live sets whose container is included and that pass filter.
+func exportChildren[T CustomData](tbl DataTable[T], table string, included map[Ref]bool, keep func(Ref, []uint32), filter func(Dataset[T]) bool) DataTable[T] {
+	list := NewDataTable[T]()
+	for _, set := range tbl {
+		container, _ := set.Container()
+		if set.Deleted || !included[container] || filter != nil && !filter(set) {
+			continue
+		}
+		list = append(list, set)
+		keep(Ref{table, set.ID}, set.Tags)
+	}
+	return list
+}
+
+// Write saves the export document to path.
+func (e *Export) Write(path string) error {
+	content, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(content, '\n'), 0644)
+}
+
+// ReadExport reads an export document and checks its format and version.
+func ReadExport(path string) (*Export, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	e := &Export{}
+	if err := json.Unmarshal(content, e); err != nil {
+		return nil, newLoadError(path, content, err)
+	}
+	if e.Format != ExportFormat {
+		return nil, fmt.Errorf("%s is not a lagerator export", path)
+	}
+	if e.Version > ExportVersion {
+		return nil, fmt.Errorf("%s was exported by a newer version of lgrt (version %d)", path, e.Version)
+	}
+	return e, nil
+}
@@ -2,16 +2,30 @@ package data
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/elsni/lagerator/terminal"
 )
 
 type Item struct {
-	Location   string `json:"location"`
-	Condition  string `json:"condition"`
-	Amount     int    `json:"amount"`
-	BoxId      uint32 `json:"boxId"`
-	CategoryId uint32 `json:"categoryId"`
+	Location      string `json:"location"`
+	Condition     string `json:"condition"`
+	Amount        int    `json:"amount"`
+	MinAmount     int    `json:"minAmount,omitempty"` // reorder at this amount, 0 for none
+	BoxId         uint32 `json:"boxId"`
+	CategoryId    uint32 `json:"categoryId"`
+	Expires       Date   `json:"expires,omitempty"`
+	UnitPrice     Money  `json:"unitPrice,omitempty"`
+	Currency      string `json:"currency,omitempty"`
+	Purchased     Date   `json:"purchased,omitempty"`
+	Vendor        string `json:"vendor,omitempty"`
+	Serial        string `json:"serial,omitempty"`
+	WarrantyUntil Date   `json:"warrantyUntil,omitempty"`
+	Receipt       string `json:"receipt,omitempty"`
+	// Ledger lists the changes of Amount made by take and put, oldest first
+	Ledger []LedgerEntry `json:"ledger,omitempty"`
+	// Loan is set while the item is lent
+	Loan *Loan `json:"loan,omitempty"`
 }
 
 // GetTableRow returns the formatted row for an item.
@@ -23,12 +37,59 @@ func (d Item) GetTableRow(ownid uint32) string {
 	roomName := GetPrintNameById(&Db.Rooms, roomId, 20)
 	whName := GetPrintNameById(&Db.Warehouses, GetWarehouseIdforRoom(roomId), 20)
 	catName := GetPrintNameById(&Db.Categories, d.CategoryId, 15)
-	return fmt.Sprintf("%-5d %-15s %-15s %-20s %-20s %-20s", d.Amount, catName, boxName, shelfName, roomName, whName)
+	return fmt.Sprintf("%-5d %-15s %-15s %-20s %-20s %-20s %s", d.Amount, catName, boxName, shelfName, roomName, whName, d.Loan.borrowerText(15))
+}
+
+// GetColumns returns the item fields with resolved box, container and category names.
+func (d Item) GetColumns(ownid uint32) []Column {
+	shelfId := GetShelfIdforBox(d.BoxId)
+	columns := []Column{
+		{"location", d.Location},
+		{"condition", d.Condition},
+		{"amount", d.Amount},
+		{"minAmount", d.MinAmount},
+		{"expires", d.Expires.ISO()},
+		{"unitPrice", d.UnitPrice.String()},
+		{"currency", d.Currency},
+		{"purchased", d.Purchased.ISO()},
+		{"vendor", d.Vendor},
+		{"serial", d.Serial},
+		{"warrantyUntil", d.WarrantyUntil.ISO()},
+		{"receipt", d.Receipt},
+		{"categoryId", d.CategoryId},
+		{"category", nameById(&Db.Categories, d.CategoryId)},
+		{"boxId", d.BoxId},
+		{"box", nameById(&Db.Boxes, d.BoxId)},
+	}
+	columns = append(columns, containerColumns(shelfId)...)
+	borrower, due := "", ""
+	if d.Loan != nil {
+		borrower = d.Loan.Borrower
+		if d.Loan.Due != 0 {
+			due = time.Unix(d.Loan.Due, 0).Format(time.DateOnly)
+		}
+	}
+	return append(columns, Column{"lentTo", borrower}, Column{"due", due})
 }
 
 // GetTableHeader returns the item table header.
 func (d Item) GetTableHeader() string {
-	return fmt.Sprintf("%-5s %-15s %-15s %-20s %-20s %-20s%s", "Amnt", "Category", "Box", "Shelf", "Room", "Warehouse", terminal.ResetColor())
+	return fmt.Sprintf("%-5s %-15s %-15s %-20s %-20s %-20s %-15s%s", "Amnt", "Category", "Box", "Shelf", "Room", "Warehouse", "Lent to", terminal.ResetColor())
+}
+
+// IsLow reports whether the amount is at or below the minimum amount.
+func (d Item) IsLow() bool {
+	return d.MinAmount > 0 && d.Amount <= d.MinAmount
+}
+
+// Expired reports whether the expiry date has passed.
+func (d Item) Expired() bool {
+	return d.Expires != 0 && d.Expires < Today()
+}
+
+// UnderWarranty reports whether the warranty has not ended yet.
+func (d Item) UnderWarranty() bool {
+	return d.WarrantyUntil >= Today()
 }
 
 // GetShelfIdforItem returns the shelf id for an item id.
@@ -47,6 +108,65 @@ func (d Item) Show() {
 	fmt.Printf("%s %s\n", terminal.GetLabelText("Location"), d.Location)
 	fmt.Printf("%s %s\n", terminal.GetLabelText("Condition"), d.Condition)
 	fmt.Printf("%s %d\n", terminal.GetLabelText("Amount"), d.Amount)
+	if d.MinAmount > 0 {
+		fmt.Printf("%s %d\n", terminal.GetLabelText("Min amount"), d.MinAmount)
+	}
 	fmt.Printf("%s %s\n", terminal.GetLabelText("Box"), GetPrintNameById(&Db.Boxes, d.BoxId, 999))
 	fmt.Printf("%s %s\n", terminal.GetLabelText("Category"), GetPrintNameById(&Db.Categories, d.CategoryId, 999))
+	if d.Expires != 0 {
+		expires := d.Expires.String()
+		if d.Expired() {
+			expires = terminal.GetWarningText(expires + ", expired")
+		}
+		fmt.Printf("%s %s\n", terminal.GetLabelText("Expires"), expires)
+	}
+	if d.UnitPrice != 0 {
+		price := Totals{}
+		price.Add(d.UnitPrice, d.Currency)
+		value := Totals{}
+		value.Add(d.Value(), d.Currency)
+		fmt.Printf("%s %s, value %s\n", terminal.GetLabelText("Unit price"), price, value)
+	}
+	if d.Purchased != 0 {
+		fmt.Printf("%s %s\n", terminal.GetLabelText("Purchased"), d.Purchased)
+	}
+	if d.Vendor != "" {
+		fmt.Printf("%s %s\n", terminal.GetLabelText("Vendor"), d.Vendor)
+	}
+	if d.Serial != "" {
+		fmt.Printf("%s %s\n", terminal.GetLabelText("Serial"), d.Serial)
+	}
+	if d.WarrantyUntil != 0 {
+		warranty := d.WarrantyUntil.String()
+		if !d.UnderWarranty() {
+			warranty += ", ended"
+		}
+		fmt.Printf("%s %s\n", terminal.GetLabelText("Warranty"), warranty)
+	}
+	if d.Receipt != "" {
+		fmt.Printf("%s %s\n", terminal.GetLabelText("Receipt"), d.Receipt)
+	}
+	if d.Loan != nil {
+		lent := fmt.Sprintf("%s since %s", d.Loan.Borrower, terminal.GetDateString(d.Loan.Since))
+		if d.Loan.Due != 0 {
+			lent += ", due " + terminal.GetDateString(d.Loan.Due)
+		}
+		if d.Loan.Overdue() {
+			lent = terminal.GetWarningText(lent + ", overdue")
+		}
+		fmt.Printf("%s %s\n", terminal.GetLabelText("Lent to"), lent)
+	}
+}
+
+// ShowExtra prints the newest ledger entries.
+func (d Item) ShowExtra(ownid uint32) {
+	if len(d.Ledger) > 0 {
+		fmt.Println()
+		entries := d.Ledger
+		if len(entries) > ledgerPreview {
+			entries = entries[len(entries)-ledgerPreview:]
+			fmt.Printf("Ledger, %d older entries not shown:\n", len(d.Ledger)-ledgerPreview)
+		}
+		PrintLedger(entries)
+	}
 }
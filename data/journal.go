@@ -0,0 +1,238 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Change holds a record before and after an operation.
+// A missing image means the record did not exist at that point. Values
+// that are not tables, like the current warehouse, use their JSON name and id 0.
+type Change struct {
+	Table  string          `json:"table"`
+	ID     uint32          `json:"id"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Operation is one entry of the journal. Undo and redo entries refer to
+// the operation they reverted or repeated by Ref.
+type Operation struct {
+	Seq     int      `json:"seq"`
+	Time    int64    `json:"time"`
+	Op      string   `json:"op"`
+	Summary string   `json:"summary"`
+	Ref     int      `json:"ref,omitempty"`
+	Changes []Change `json:"changes,omitempty"`
+}
+
+const (
+	OpUndo = "undo"
+	OpRedo = "redo"
+)
+
+// ErrJournal is returned by SaveChanges when the database was saved but the
+// operation could not be journaled.
+var ErrJournal = errors.New("could not write journal")
+
+// JournalPath returns the journal file kept next to the database.
+func JournalPath() (string, error) {
+	path, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return path + ".journal", nil
+}
+
+// ReadJournal returns all journal entries in order, a missing journal is empty.
+func ReadJournal() ([]Operation, error) {
+	path, err := JournalPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var ops []Operation
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var op Operation
+		if err := json.Unmarshal(scanner.Bytes(), &op); err != nil {
+			// a torn last line from a crash is ignored
+			continue
+		}
+		ops = append(ops, op)
+	}
+	return ops, scanner.Err()
+}
+
+// journalTail is the number of bytes lastSeq reads first from the end of
+// the journal. It doubles until a complete entry is found.
+const journalTail = 64 * 1024
+
+// lastSeq returns the sequence number of the last entry of an open journal,
+// 0 if it is empty, and whether the file ends within a line. Only the end
+// of the file is read.
+func lastSeq(f *os.File) (int, bool, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, false, err
+	}
+	size := info.Size()
+	for tail := int64(journalTail); ; tail *= 2 {
+		from := max(size-tail, 0)
+		buf := make([]byte, size-from)
+		if _, err := f.ReadAt(buf, from); err != nil {
+			return 0, false, err
+		}
+		torn := len(buf) > 0 && buf[len(buf)-1] != '\n'
+		lines := bytes.Split(buf, []byte("\n"))
+		// the first line may be cut off unless the whole file was read
+		first := 1
+		if from == 0 {
+			first = 0
+		}
+		for i := len(lines) - 1; i >= first; i-- {
+			if len(bytes.TrimSpace(lines[i])) == 0 {
+				continue
+			}
+			var op Operation
+			// a torn last line from a crash is skipped like in ReadJournal
+			if json.Unmarshal(lines[i], &op) == nil {
+				return op.Seq, torn, nil
+			}
+		}
+		if from == 0 {
+			return 0, torn, nil
+		}
+	}
+}
+
+// AppendJournal numbers an operation and appends it to the journal while
+// holding the database lock.
+func AppendJournal(op *Operation) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	lock, err := LockPath(path)
+	if err != nil {
+		return fmt.Errorf("lock database: %w", err)
+	}
+	defer lock.Unlock()
+	return appendJournal(op)
+}
+
+// appendJournal is AppendJournal for a caller holding the database lock.
+func appendJournal(op *Operation) error {
+	path, err := JournalPath()
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	seq, torn, err := lastSeq(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	op.Seq = seq + 1
+	if op.Time == 0 {
+		op.Time = time.Now().Unix()
+	}
+	line, err := json.Marshal(op)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	line = append(line, '\n')
+	if torn {
+		// end the torn line, so the entry starts on a line of its own
+		line = append([]byte("\n"), line...)
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// JournalStacks replays the journal and returns the operations that can be
+// undone and redone, the next candidate last. undone marks reverted operations.
+func JournalStacks(ops []Operation) (undoable []Operation, redoable []Operation, undone map[int]bool) {
+	undone = map[int]bool{}
+	for _, op := range ops {
+		switch op.Op {
+		case OpUndo:
+			if n := len(undoable); n > 0 && undoable[n-1].Seq == op.Ref {
+				redoable = append(redoable, undoable[n-1])
+				undoable = undoable[:n-1]
+				undone[op.Ref] = true
+			}
+		case OpRedo:
+			if n := len(redoable); n > 0 && redoable[n-1].Seq == op.Ref {
+				undoable = append(undoable, redoable[n-1])
+				redoable = redoable[:n-1]
+				undone[op.Ref] = false
+			}
+		default:
+			undoable = append(undoable, op)
+			// a new change makes the undone operations unreachable
+			redoable = nil
+		}
+	}
+	return undoable, redoable, undone
+}
+
+// ApplyChanges restores the before images (undo) or the after images (redo).
+// It refuses to apply anything if a record changed since the operation.
+func (db *Database) ApplyChanges(changes []Change, undo bool) error {
+	for _, c := range changes {
+		expected := c.After
+		if !undo {
+			expected = c.Before
+		}
+		current, _ := db.GetRecord(c.Table, c.ID)
+		if !bytes.Equal(current, expected) {
+			return fmt.Errorf("%s %d was changed afterwards", c.Table, c.ID)
+		}
+	}
+	for i := range changes {
+		c := changes[i]
+		image := c.After
+		if undo {
+			c = changes[len(changes)-1-i]
+			image = c.Before
+		}
+		var err error
+		if image == nil {
+			err = db.RemoveRecord(c.Table, c.ID)
+		} else {
+			err = db.PutRecord(c.Table, image)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
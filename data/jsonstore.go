@@ -0,0 +1,191 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// JSONStore keeps the whole database in a single JSON file.
+type JSONStore struct {
+	path    string
+	content []byte
+}
+
+// NewJSONStore returns a store for the JSON file at path.
+func NewJSONStore(path string) *JSONStore {
+	return &JSONStore{path: path}
+}
+
+// Path returns the database file name.
+func (s *JSONStore) Path() string {
+	return s.path
+}
+
+// Load reads and splits the JSON file.
+func (s *JSONStore) Load() (Document, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	var doc Document
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, newLoadError(s.path, content, err)
+	}
+	s.content = content
+	return doc, nil
+}
+
+// locate turns a table decode error into a LoadError with file position.
+func (s *JSONStore) locate(doc Document, tableErr *TableError) error {
+	var typeErr *json.UnmarshalTypeError
+	start := bytes.Index(s.content, doc[tableErr.Table])
+	if start < 0 || !errors.As(tableErr.Err, &typeErr) {
+		return &LoadError{Path: s.path, Err: tableErr}
+	}
+	shifted := *typeErr
+	shifted.Offset += int64(start)
+	loadErr := newLoadError(s.path, s.content, &shifted)
+	loadErr.Err = tableErr
+	return loadErr
+}
+
+// Save writes the file atomically and rotates the previous file into the backups.
+func (s *JSONStore) Save(doc Document) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), os.ModePerm); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+	content, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("encode database: %w", err)
+	}
+	if err := rotateBackups(s.path, BackupGenerations); err != nil {
+		return fmt.Errorf("rotate backups: %w", err)
+	}
+	if err := writeFileAtomic(s.path, content, 0644); err != nil {
+		return fmt.Errorf("write %s: %w", s.path, err)
+	}
+	s.content = content
+	return nil
+}
+
+// loadTable returns the document and the records of one table.
+func (s *JSONStore) loadTable(table string) (Document, []json.RawMessage, error) {
+	doc, err := s.Load()
+	if errors.Is(err, os.ErrNotExist) {
+		return Document{}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var records []json.RawMessage
+	if raw, ok := doc[table]; ok {
+		if err := json.Unmarshal(raw, &records); err != nil {
+			return nil, nil, &TableError{Table: table, Err: err}
+		}
+	}
+	return doc, records, nil
+}
+
+// saveTable replaces one table in the document and writes the file.
+func (s *JSONStore) saveTable(doc Document, table string, records []json.RawMessage) error {
+	if records == nil {
+		records = []json.RawMessage{}
+	}
+	raw, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	doc[table] = raw
+	return s.Save(doc)
+}
+
+// Get returns a single record of a table.
+func (s *JSONStore) Get(table string, id uint32) (json.RawMessage, bool, error) {
+	found := json.RawMessage(nil)
+	err := s.Query(table, func(record json.RawMessage) bool {
+		if rid, err := recordId(record); err == nil && rid == id {
+			found = record
+			return false
+		}
+		return true
+	})
+	return found, found != nil, err
+}
+
+// Put inserts or replaces a single record of a table.
+func (s *JSONStore) Put(table string, id uint32, record json.RawMessage) error {
+	doc, records, err := s.loadTable(table)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i, r := range records {
+		if rid, err := recordId(r); err == nil && rid == id {
+			records[i] = record
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		records = append(records, record)
+	}
+	return s.saveTable(doc, table, records)
+}
+
+// Delete physically removes a single record of a table.
+func (s *JSONStore) Delete(table string, id uint32) error {
+	doc, records, err := s.loadTable(table)
+	if err != nil {
+		return err
+	}
+	kept := make([]json.RawMessage, 0, len(records))
+	for _, r := range records {
+		if rid, err := recordId(r); err == nil && rid == id {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return s.saveTable(doc, table, kept)
+}
+
+// Query calls fn for every record of a table until fn returns false.
+func (s *JSONStore) Query(table string, fn func(record json.RawMessage) bool) error {
+	_, records, err := s.loadTable(table)
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		if !fn(r) {
+			break
+		}
+	}
+	return nil
+}
+
+// Revision returns the revision counter stored in the file, 0 if there is no file.
+func (s *JSONStore) Revision() (uint64, error) {
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var head struct {
+		Revision uint64 `json:"revision"`
+	}
+	if err := json.Unmarshal(content, &head); err != nil {
+		return 0, newLoadError(s.path, content, err)
+	}
+	return head.Revision, nil
+}
+
+// Close releases the cached file content.
+func (s *JSONStore) Close() error {
+	s.content = nil
+	return nil
+}
@@ -0,0 +1,48 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elsni/lagerator/terminal"
+)
+
+// LedgerEntry records a change of the amount of an item.
+type LedgerEntry struct {
+	Time   int64  `json:"time"`
+	Change int    `json:"change"`
+	Amount int    `json:"amount"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ledgerPreview is the number of entries Show prints.
+const ledgerPreview = 5
+
+// Adjust changes the amount by change and records it in the ledger.
+// The amount cannot drop below zero.
+func (d *Item) Adjust(change int, reason string) error {
+	if d.Amount+change < 0 {
+		return fmt.Errorf("only %d left", d.Amount)
+	}
+	d.Amount += change
+	d.Ledger = append(d.Ledger, LedgerEntry{Time: time.Now().Unix(), Change: change, Amount: d.Amount, Reason: reason})
+	return nil
+}
+
+// LedgerSince returns the ledger entries recorded at or after since.
+func (d Item) LedgerSince(since int64) []LedgerEntry {
+	for i, e := range d.Ledger {
+		if e.Time >= since {
+			return d.Ledger[i:]
+		}
+	}
+	return nil
+}
+
+// PrintLedger prints ledger entries as a table.
+func PrintLedger(entries []LedgerEntry) {
+	fmt.Println(terminal.GetHeadlineText(fmt.Sprintf("%-16s %7s %7s %-40s", "Time", "Change", "Amount", "Reason")))
+	for _, e := range entries {
+		fmt.Printf("%-16s %+7d %7d %s\n", terminal.GetTimeString(e.Time), e.Change, e.Amount, e.Reason)
+	}
+}
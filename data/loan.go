@@ -0,0 +1,37 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elsni/lagerator/terminal"
+)
+
+// Loan records who borrowed an item.
+type Loan struct {
+	Borrower string `json:"borrower"`
+	Since    int64  `json:"since"`
+	// Due is the end of the day the item is expected back, 0 if there is no due date
+	Due int64 `json:"due,omitempty"`
+}
+
+// Overdue reports whether the due date of the loan has passed.
+func (l *Loan) Overdue() bool {
+	return l != nil && l.Due != 0 && time.Now().Unix() > l.Due
+}
+
+// borrowerText returns the borrower padded to width, red if overdue.
+func (l *Loan) borrowerText(width int) string {
+	if l == nil {
+		return fmt.Sprintf("%-*s", width, "")
+	}
+	name := l.Borrower
+	if len([]rune(name)) > width {
+		name = string([]rune(name)[:width-1]) + "…"
+	}
+	text := fmt.Sprintf("%-*s", width, name)
+	if l.Overdue() {
+		return terminal.GetWarningText(text)
+	}
+	return text
+}
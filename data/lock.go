@@ -0,0 +1,42 @@
+package data
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrConflict is returned by Save when another process saved the database
+// after it was loaded.
+var ErrConflict = errors.New("database changed underneath you")
+
+// FileLock is an advisory lock on a database file.
+type FileLock struct {
+	f *os.File
+}
+
+// LockPath acquires an exclusive advisory lock for path, waiting for other holders.
+// The lock is taken on a separate file so atomic renames of path don't affect it.
+func LockPath(path string) (*FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &FileLock{f: f}, nil
+}
+
+// Unlock releases the lock.
+func (l *FileLock) Unlock() error {
+	err := unlockFile(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package data
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile blocks until an exclusive flock on f is acquired.
+func lockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+// unlockFile releases the flock on f.
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,169 @@
+package data
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OutputFormat selects how lists and details are printed.
+type OutputFormat string
+
+const (
+	OutputText OutputFormat = "text"
+	OutputJSON OutputFormat = "json"
+	OutputCSV  OutputFormat = "csv"
+	OutputTSV  OutputFormat = "tsv"
+)
+
+// Output is the format used by PrintListFiltered and Show.
+var Output = OutputText
+
+// ParseOutputFormat checks the name of an output format.
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	switch f := OutputFormat(strings.ToLower(name)); f {
+	case OutputText, OutputJSON, OutputCSV, OutputTSV:
+		return f, nil
+	}
+	return OutputText, fmt.Errorf("unknown output format \"%s\", use text, json, csv or tsv", name)
+}
+
+// Column is a named value of a record in machine-readable output.
+type Column struct {
+	Name  string
+	Value any
+}
+
+// GetColumns returns the dataset with resolved references as columns.
+func (d Dataset[T]) GetColumns() []Column {
+	tagnames := []string{}
+	for _, id := range d.Tags {
+		if tag, ok := Db.Tags.GetPtr(id); ok {
+			tagnames = append(tagnames, tag.Name)
+		}
+	}
+	tagids := d.Tags
+	if tagids == nil {
+		tagids = []uint32{}
+	}
+	columns := []Column{
+		{"id", d.ID},
+		{"name", d.Name},
+		{"description", d.Description},
+		{"created", time.Unix(d.Created, 0)},
+		{"updated", time.Unix(d.Updated, 0)},
+		{"tags", tagnames},
+		{"tagIds", tagids},
+	}
+	return append(columns, d.Data.GetColumns(d.ID)...)
+}
+
+// nameById returns the name of a live dataset, or "" if there is none.
+func nameById[T CustomData](tbl *DataTable[T], id uint32) string {
+	if set, ok := tbl.GetPtr(id); ok {
+		return set.Name
+	}
+	return ""
+}
+
+// containerColumns returns the ids and names of a shelf and its containers.
+func containerColumns(shelfId uint32) []Column {
+	roomId := GetRoomIdforShelf(shelfId)
+	warehouseId := GetWarehouseIdforRoom(roomId)
+	return []Column{
+		{"shelfId", shelfId},
+		{"shelf", nameById(&Db.Shelves, shelfId)},
+		{"roomId", roomId},
+		{"room", nameById(&Db.Rooms, roomId)},
+		{"warehouseId", warehouseId},
+		{"warehouse", nameById(&Db.Warehouses, warehouseId)},
+	}
+}
+
+// WriteSets prints datasets in the machine-readable Output format.
+func WriteSets[T CustomData](sets []Dataset[T]) {
+	var rows [][]Column
+	for _, set := range sets {
+		rows = append(rows, set.GetColumns())
+	}
+	if len(rows) == 0 {
+		// the column names are needed for the CSV header of an empty list
+		var empty Dataset[T]
+		writeRows(nil, empty.GetColumns())
+		return
+	}
+	writeRows(rows, rows[0])
+}
+
+// writeRows prints rows as JSON array or as CSV/TSV with a header from header.
+func writeRows(rows [][]Column, header []Column) {
+	if Output == OutputJSON {
+		var buf bytes.Buffer
+		buf.WriteString("[")
+		for i, row := range rows {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n  {")
+			for j, col := range row {
+				if j > 0 {
+					buf.WriteString(", ")
+				}
+				key, _ := json.Marshal(col.Name)
+				value, err := json.Marshal(col.Value)
+				if err != nil {
+					value = []byte("null")
+				}
+				buf.Write(key)
+				buf.WriteString(": ")
+				buf.Write(value)
+			}
+			buf.WriteString("}")
+		}
+		if len(rows) > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("]\n")
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	w := csv.NewWriter(os.Stdout)
+	if Output == OutputTSV {
+		w.Comma = '\t'
+	}
+	names := make([]string, len(header))
+	for i, col := range header {
+		names[i] = col.Name
+	}
+	w.Write(names)
+	for _, row := range rows {
+		values := make([]string, len(row))
+		for i, col := range row {
+			values[i] = csvValue(col.Value)
+		}
+		w.Write(values)
+	}
+	w.Flush()
+}
+
+// csvValue formats a column value for a CSV field.
+func csvValue(value any) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []string:
+		return strings.Join(v, ", ")
+	case []uint32:
+		ids := make([]string, len(v))
+		for i, id := range v {
+			ids[i] = strconv.FormatUint(uint64(id), 10)
+		}
+		return strings.Join(ids, ", ")
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,406 @@
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Item search queries, for example
+//
+//	name:drill tag:broken cat:tools room:Basement amount<2 updated>2026-01-01 -tag:lent "exact phrase"
+//
+// Terms are combined with AND unless joined by OR, NOT or a leading - negates
+// a term and parentheses group terms. A plain word is looked up in the search
+// index by stem and prefix, a "phrase" is searched in the name, description and
+// location of an item.
+
+// fieldKind tells how the value of a query field is compared.
+type fieldKind int
+
+const (
+	textField fieldKind = iota
+	tagField
+	numberField
+	dateField
+	moneyField
+)
+
+// queryField is a field that can be used as name:value or name<value.
+type queryField struct {
+	kind   fieldKind
+	text   func(set Dataset[Item]) string
+	number func(set Dataset[Item]) int64
+}
+
+// queryFields lists the fields of the query language.
+var queryFields = map[string]queryField{
+	"name":      {kind: textField, text: func(s Dataset[Item]) string { return s.Name }},
+	"desc":      {kind: textField, text: func(s Dataset[Item]) string { return s.Description }},
+	"loc":       {kind: textField, text: func(s Dataset[Item]) string { return s.Data.Location }},
+	"condition": {kind: textField, text: func(s Dataset[Item]) string { return s.Data.Condition }},
+	"vendor":    {kind: textField, text: func(s Dataset[Item]) string { return s.Data.Vendor }},
+	"serial":    {kind: textField, text: func(s Dataset[Item]) string { return s.Data.Serial }},
+	"lent":      {kind: textField, text: borrower},
+	"cat":       {kind: textField, text: func(s Dataset[Item]) string { return nameOf(Ref{"categories", s.Data.CategoryId}) }},
+	"box":       {kind: textField, text: func(s Dataset[Item]) string { return nameOf(ItemPath(s.Data)[0]) }},
+	"shelf":     {kind: textField, text: func(s Dataset[Item]) string { return nameOf(ItemPath(s.Data)[1]) }},
+	"room":      {kind: textField, text: func(s Dataset[Item]) string { return nameOf(ItemPath(s.Data)[2]) }},
+	"warehouse": {kind: textField, text: func(s Dataset[Item]) string { return nameOf(ItemPath(s.Data)[3]) }},
+	"tag":       {kind: tagField},
+	"id":        {kind: numberField, number: func(s Dataset[Item]) int64 { return int64(s.ID) }},
+	"amount":    {kind: numberField, number: func(s Dataset[Item]) int64 { return int64(s.Data.Amount) }},
+	"min":       {kind: numberField, number: func(s Dataset[Item]) int64 { return int64(s.Data.MinAmount) }},
+	"price":     {kind: moneyField, number: func(s Dataset[Item]) int64 { return int64(s.Data.UnitPrice) }},
+	"created":   {kind: dateField, number: func(s Dataset[Item]) int64 { return int64(DateOf(time.Unix(s.Created, 0))) }},
+	"updated":   {kind: dateField, number: func(s Dataset[Item]) int64 { return int64(DateOf(time.Unix(s.Updated, 0))) }},
+	"expires":   {kind: dateField, number: func(s Dataset[Item]) int64 { return int64(s.Data.Expires) }},
+	"purchased": {kind: dateField, number: func(s Dataset[Item]) int64 { return int64(s.Data.Purchased) }},
+	"warranty":  {kind: dateField, number: func(s Dataset[Item]) int64 { return int64(s.Data.WarrantyUntil) }},
+}
+
+// queryAliases maps other names of fields to their entry in queryFields.
+var queryAliases = map[string]string{"description": "desc", "location": "loc", "category": "cat", "wh": "warehouse"}
+
+// termPattern splits a term into field, operator and value.
+var termPattern = regexp.MustCompile(`(?s)^([A-Za-z]+)(:|<=|>=|<|>|=)(.*)$`)
+
+// borrower returns the person an item is lent to.
+func borrower(s Dataset[Item]) string {
+	if s.Data.Loan == nil {
+		return ""
+	}
+	return s.Data.Loan.Borrower
+}
+
+// nameOf returns the name of a record, empty if it does not exist.
+func nameOf(ref Ref) string {
+	info, _ := Db.Record(ref)
+	return info.Name
+}
+
+// fieldNames returns the names of the query fields for error messages.
+func fieldNames() string {
+	var names []string
+	for name := range queryFields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// tokenKind is the kind of a query token.
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokPhrase
+	tokOpen
+	tokClose
+	tokAnd
+	tokOr
+	tokNot
+)
+
+// queryToken is a word, phrase, parenthesis or operator of a query.
+type queryToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// tokenize splits a query into tokens. Positions start at 1.
+func tokenize(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{tokOpen, "(", pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{tokClose, ")", pos})
+			i++
+		case r == '-' && i+1 < len(runes) && !strings.ContainsRune(" \t\n)", runes[i+1]):
+			tokens = append(tokens, queryToken{tokNot, "-", pos})
+			i++
+		case r == '"':
+			end := slices.Index(runes[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("missing closing quote for the quote at position %d", pos)
+			}
+			tokens = append(tokens, queryToken{tokPhrase, string(runes[i+1 : i+1+end]), pos})
+			i += end + 2
+		default:
+			var word strings.Builder
+			quoted := false
+			for i < len(runes) && !strings.ContainsRune(" \t\n()", runes[i]) {
+				if runes[i] == '"' {
+					end := slices.Index(runes[i+1:], '"')
+					if end < 0 {
+						return nil, fmt.Errorf("missing closing quote for the quote at position %d", i+1)
+					}
+					word.WriteString(string(runes[i+1 : i+1+end]))
+					i += end + 2
+					quoted = true
+					continue
+				}
+				word.WriteRune(runes[i])
+				i++
+			}
+			token := queryToken{tokWord, word.String(), pos}
+			if !quoted {
+				switch token.text {
+				case "AND":
+					token.kind = tokAnd
+				case "OR":
+					token.kind = tokOr
+				case "NOT":
+					token.kind = tokNot
+				}
+			}
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+// queryParser builds a filter from the tokens of a query.
+type queryParser struct {
+	tokens []queryToken
+	next   int
+}
+
+// ParseQuery turns an item search query into a filter for PrintListFiltered.
+func ParseQuery(query string) (func(Dataset[Item]) bool, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+	p := &queryParser{tokens: tokens}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected \"%s\" at position %d", token.text, token.pos)
+	}
+	return filter, nil
+}
+
+// peek returns the next token without consuming it.
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.next >= len(p.tokens) {
+		return queryToken{}, false
+	}
+	return p.tokens[p.next], true
+}
+
+// operand consumes an operator and checks that a term follows it.
+func (p *queryParser) operand(op queryToken) error {
+	p.next++
+	if token, ok := p.peek(); !ok || token.kind == tokClose || token.kind == tokAnd || token.kind == tokOr {
+		return fmt.Errorf("%s at position %d needs a term after it", op.text, op.pos)
+	}
+	return nil
+}
+
+// parseOr parses terms joined by OR.
+func (p *queryParser) parseOr() (func(Dataset[Item]) bool, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token, ok := p.peek()
+		if !ok || token.kind != tokOr {
+			return left, nil
+		}
+		if err := p.operand(token); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(s Dataset[Item]) bool { return l(s) || right(s) }
+	}
+}
+
+// parseAnd parses terms joined by AND or just written one after the other.
+func (p *queryParser) parseAnd() (func(Dataset[Item]) bool, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token, ok := p.peek()
+		if !ok || token.kind == tokOr || token.kind == tokClose {
+			return left, nil
+		}
+		if token.kind == tokAnd {
+			if err := p.operand(token); err != nil {
+				return nil, err
+			}
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(s Dataset[Item]) bool { return l(s) && right(s) }
+	}
+}
+
+// parseUnary parses a negated term, a group in parentheses or a single term.
+func (p *queryParser) parseUnary() (func(Dataset[Item]) bool, error) {
+	token, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of the query")
+	}
+	switch token.kind {
+	case tokNot:
+		if err := p.operand(token); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(s Dataset[Item]) bool { return !inner(s) }, nil
+	case tokOpen:
+		p.next++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next, ok := p.peek(); !ok || next.kind != tokClose {
+			return nil, fmt.Errorf("missing \")\" for the \"(\" at position %d", token.pos)
+		}
+		p.next++
+		return inner, nil
+	case tokClose:
+		return nil, fmt.Errorf("unexpected \")\" at position %d", token.pos)
+	case tokAnd, tokOr:
+		return nil, fmt.Errorf("%s at position %d needs a term before it", token.text, token.pos)
+	case tokPhrase:
+		p.next++
+		return matchText(token.text), nil
+	}
+	p.next++
+	return parseTerm(token)
+}
+
+// matchText matches a word or phrase in the name, description and location.
+func matchText(text string) func(Dataset[Item]) bool {
+	text = strings.ToLower(text)
+	return func(s Dataset[Item]) bool {
+		return strings.Contains(strings.ToLower(s.Name), text) ||
+			strings.Contains(strings.ToLower(s.Description), text) ||
+			strings.Contains(strings.ToLower(s.Data.Location), text)
+	}
+}
+
+// matchWord matches a plain word by stem in the fields of the search index.
+func matchWord(text string) func(Dataset[Item]) bool {
+	ids := Db.SearchIndex().Lookup(text)
+	return func(s Dataset[Item]) bool { return ids[s.ID] }
+}
+
+// parseTerm parses a plain word or a field term like name:drill or amount<2.
+func parseTerm(token queryToken) (func(Dataset[Item]) bool, error) {
+	m := termPattern.FindStringSubmatch(token.text)
+	if m == nil {
+		return matchWord(token.text), nil
+	}
+	name, op, value := strings.ToLower(m[1]), m[2], m[3]
+	if alias, ok := queryAliases[name]; ok {
+		name = alias
+	}
+	field, ok := queryFields[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown field \"%s\" at position %d, use one of %s", m[1], token.pos, fieldNames())
+	}
+	if value == "" {
+		return nil, fmt.Errorf("missing value after \"%s%s\" at position %d", m[1], op, token.pos)
+	}
+	switch field.kind {
+	case textField, tagField:
+		if op != ":" && op != "=" {
+			return nil, fmt.Errorf("%s at position %d cannot be compared with %s, use %s:%s", m[1], token.pos, op, m[1], value)
+		}
+		return textTerm(field, op, value), nil
+	}
+	var number int64
+	switch field.kind {
+	case numberField:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s at position %d needs a number, not \"%s\"", m[1], token.pos, value)
+		}
+		number = n
+	case moneyField:
+		money, err := ParseMoney(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s at position %d: %v", m[1], token.pos, err)
+		}
+		number = int64(money)
+	case dateField:
+		date, err := ParseDate(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s at position %d: %v", m[1], token.pos, err)
+		}
+		number = int64(date)
+	}
+	return func(s Dataset[Item]) bool {
+		n := field.number(s)
+		if field.kind == dateField && n == 0 {
+			return false
+		}
+		switch op {
+		case "<":
+			return n < number
+		case "<=":
+			return n <= number
+		case ">":
+			return n > number
+		case ">=":
+			return n >= number
+		}
+		return n == number
+	}, nil
+}
+
+// textTerm matches a text field, name:value finds the value anywhere in the
+// field and name=value only the whole field. Tags always match whole names.
+func textTerm(field queryField, op string, value string) func(Dataset[Item]) bool {
+	value = strings.ToLower(value)
+	if field.kind == tagField {
+		return func(s Dataset[Item]) bool {
+			for _, id := range s.Tags {
+				if tag, ok := Db.Tags.GetPtr(id); ok && strings.ToLower(tag.Name) == value {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return func(s Dataset[Item]) bool {
+		text := strings.ToLower(field.text(s))
+		if op == "=" {
+			return text == value
+		}
+		return strings.Contains(text, value)
+	}
+}
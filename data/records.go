@@ -0,0 +1,151 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// recordTable gives untyped access to the records of a table,
+// deleted records included.
+type recordTable interface {
+	getRecord(id uint32) (json.RawMessage, bool)
+	putRecord(record json.RawMessage) error
+	removeRecord(id uint32)
+	record(id uint32) (RecordInfo, bool)
+	trash() []RecordInfo
+	restore(id uint32)
+	parents(id uint32) []Ref
+	children(parent Ref) []Ref
+	tagged(tagid uint32) []Ref
+	untag(id uint32, tagid uint32)
+	softDelete(id uint32)
+	reparent(id uint32, from Ref, to uint32)
+}
+
+// GetIdxAny returns the index for an id including deleted entries, or -1.
+func (dt *DataTable[T]) GetIdxAny(id uint32) int {
+	ix := dt.index()
+	if !ix.dups {
+		if i, ok := ix.ids[id]; ok {
+			return i
+		}
+		return -1
+	}
+	// prefer a live entry if the id is duplicated
+	if i := dt.GetIdx(id); i > -1 {
+		return i
+	}
+	for i, set := range *dt {
+		if set.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// getRecord returns the JSON form of a dataset.
+func (dt *DataTable[T]) getRecord(id uint32) (json.RawMessage, bool) {
+	idx := dt.GetIdxAny(id)
+	if idx < 0 {
+		return nil, false
+	}
+	record, err := json.Marshal((*dt)[idx])
+	if err != nil {
+		return nil, false
+	}
+	return record, true
+}
+
+// putRecord replaces the dataset with the record's id or appends it.
+func (dt *DataTable[T]) putRecord(record json.RawMessage) error {
+	var set Dataset[T]
+	if err := json.Unmarshal(record, &set); err != nil {
+		return err
+	}
+	if idx := dt.GetIdxAny(set.ID); idx > -1 {
+		dt.Replace(idx, set)
+		return nil
+	}
+	dt.appendIndexed(set)
+	return nil
+}
+
+// removeRecord physically removes a dataset.
+func (dt *DataTable[T]) removeRecord(id uint32) {
+	if idx := dt.GetIdxAny(id); idx > -1 {
+		*dt = slices.Delete(*dt, idx, idx+1)
+		dt.dropIndex()
+	}
+}
+
+// table returns raw access to the table with the given JSON name.
+func (db *Database) table(name string) (recordTable, error) {
+	tbl, ok := db.fields()[name].(recordTable)
+	if !ok {
+		return nil, fmt.Errorf("unknown table \"%s\"", name)
+	}
+	return tbl, nil
+}
+
+// TableName returns the JSON name of a table of the database, or "".
+func (db *Database) TableName(tbl any) string {
+	for name, field := range db.fields() {
+		if field == tbl {
+			return name
+		}
+	}
+	return ""
+}
+
+// value returns a database value that is not a table, like the current
+// warehouse. The journal handles it as a record with id 0.
+func (db *Database) value(name string) (any, bool) {
+	field, ok := db.fields()[name]
+	if _, isTable := field.(recordTable); !ok || isTable {
+		return nil, false
+	}
+	return field, true
+}
+
+// GetRecord returns the JSON form of a record, deleted records included.
+func (db *Database) GetRecord(table string, id uint32) (json.RawMessage, bool) {
+	if field, ok := db.value(table); ok {
+		record, err := json.Marshal(field)
+		return record, err == nil
+	}
+	tbl, err := db.table(table)
+	if err != nil {
+		return nil, false
+	}
+	return tbl.getRecord(id)
+}
+
+// PutRecord inserts or replaces a record given in JSON form.
+func (db *Database) PutRecord(table string, record json.RawMessage) error {
+	if field, ok := db.value(table); ok {
+		// decoding into a map would keep the keys missing in record
+		reflect.ValueOf(field).Elem().SetZero()
+		return json.Unmarshal(record, field)
+	}
+	tbl, err := db.table(table)
+	if err != nil {
+		return err
+	}
+	return tbl.putRecord(record)
+}
+
+// RemoveRecord physically removes a record.
+func (db *Database) RemoveRecord(table string, id uint32) error {
+	if field, ok := db.value(table); ok {
+		reflect.ValueOf(field).Elem().SetZero()
+		return nil
+	}
+	tbl, err := db.table(table)
+	if err != nil {
+		return err
+	}
+	tbl.removeRecord(id)
+	return nil
+}
@@ -0,0 +1,181 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/elsni/lagerator/id"
+)
+
+// LoadError describes where the database file failed to decode.
+type LoadError struct {
+	Path   string
+	Offset int64
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error returns the error with file position.
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("%s:%d:%d (offset %d): %v", e.Path, e.Line, e.Column, e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying decoder error.
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// newLoadError builds a LoadError and resolves the offset to line and column.
+func newLoadError(path string, content []byte, err error) *LoadError {
+	var offset int64 = int64(len(content))
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) {
+		// the decoder reports the offset after the offending byte
+		offset = max(syntaxErr.Offset-1, 0)
+	} else if errors.As(err, &typeErr) {
+		offset = typeErr.Offset
+	}
+	if offset > int64(len(content)) {
+		offset = int64(len(content))
+	}
+	before := content[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+	return &LoadError{Path: path, Offset: offset, Line: line, Column: column, Err: err}
+}
+
+// TableReport holds the salvage result for one table.
+type TableReport struct {
+	Name      string
+	Kept      int
+	Dropped   int
+	Missing   bool
+	Truncated bool
+}
+
+// RepairReport summarizes what Repair kept and dropped.
+type RepairReport struct {
+	Clean  bool
+	Tables []TableReport
+	Notes  []string
+}
+
+// Repair salvages every table of a corrupted database file and saves the result.
+// The corrupted file is kept as the newest backup generation.
+func (db *Database) Repair() (RepairReport, error) {
+	path, err := Path()
+	if err != nil {
+		return RepairReport{}, err
+	}
+	if IsSQLitePath(path) {
+		return RepairReport{}, fmt.Errorf("repair supports JSON databases only")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return RepairReport{}, err
+	}
+	if json.Unmarshal(content, NewDatabase()) == nil {
+		return RepairReport{Clean: true}, nil
+	}
+	salvaged, report := Salvage(content)
+	*db = *salvaged
+	id.IdSource.SetLastId(db.FindLastId())
+	return report, db.ForceSave()
+}
+
+// Salvage decodes as much of a damaged database document as possible.
+func Salvage(content []byte) (*Database, RepairReport) {
+	db := NewDatabase()
+	report := RepairReport{}
+	raws, note := salvageObject(content)
+	if note != "" {
+		report.Notes = append(report.Notes, note)
+	}
+
+	// the salvaged tables keep their layout, so the next Load migrates them
+	db.SchemaVersion = 0
+	if raw, ok := raws["schemaVersion"]; ok {
+		json.Unmarshal(raw, &db.SchemaVersion)
+	}
+	if raw, ok := raws["currentWarehouseid"]; !ok || json.Unmarshal(raw, &db.CurrentWarehouse) != nil {
+		report.Notes = append(report.Notes, "current warehouse was lost")
+	}
+	report.Tables = append(report.Tables,
+		salvageTable(&db.Warehouses, "warehouses", raws),
+		salvageTable(&db.Rooms, "rooms", raws),
+		salvageTable(&db.Shelves, "shelves", raws),
+		salvageTable(&db.Boxes, "boxes", raws),
+		salvageTable(&db.Items, "items", raws),
+		salvageTable(&db.Categories, "categories", raws),
+		salvageTable(&db.Tags, "tags", raws),
+	)
+	return db, report
+}
+
+// salvageObject splits the top-level object into raw values per key.
+// Decoding stops at the first damaged value, which is kept as its unparsed remainder.
+func salvageObject(content []byte) (map[string][]byte, string) {
+	raws := map[string][]byte{}
+	dec := json.NewDecoder(bytes.NewReader(content))
+	tok, err := dec.Token()
+	if err != nil || tok != json.Delim('{') {
+		return raws, "file does not start with a JSON object"
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return raws, fmt.Sprintf("file is damaged at offset %d", dec.InputOffset())
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return raws, fmt.Sprintf("unexpected token at offset %d", dec.InputOffset())
+		}
+		start := dec.InputOffset()
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			raws[key] = bytes.TrimLeft(content[start:], " \t\r\n:")
+			return raws, fmt.Sprintf("file is damaged inside \"%s\"", key)
+		}
+		raws[key] = value
+	}
+	return raws, ""
+}
+
+// salvageTable decodes a table element by element and drops broken entries.
+func salvageTable[T CustomData](tbl *DataTable[T], name string, raws map[string][]byte) TableReport {
+	report := TableReport{Name: name}
+	raw, ok := raws[name]
+	if !ok {
+		report.Missing = true
+		return report
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	tok, err := dec.Token()
+	if tok == nil && err == nil {
+		return report
+	}
+	if err != nil || tok != json.Delim('[') {
+		report.Missing = true
+		return report
+	}
+	for dec.More() {
+		var element json.RawMessage
+		if err := dec.Decode(&element); err != nil {
+			report.Truncated = true
+			break
+		}
+		var set Dataset[T]
+		if err := json.Unmarshal(element, &set); err != nil {
+			report.Dropped++
+			continue
+		}
+		*tbl = append(*tbl, set)
+		report.Kept++
+	}
+	return report
+}
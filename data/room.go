@@ -17,6 +17,15 @@ func (d Room) GetTableRow(ownid uint32) string {
 	return fmt.Sprintf("%-20s", GetPrintNameById(&Db.Warehouses, d.WarehouseId, 20))
 }
 
+// GetColumns returns the room fields with the resolved warehouse name.
+func (d Room) GetColumns(ownid uint32) []Column {
+	return []Column{
+		{"location", d.Location},
+		{"warehouseId", d.WarehouseId},
+		{"warehouse", nameById(&Db.Warehouses, d.WarehouseId)},
+	}
+}
+
 // GetTableHeader returns the room table header.
 func (d Room) GetTableHeader() string {
 	return fmt.Sprintf("%-20s%s", "Warehouse", terminal.ResetColor())
@@ -31,6 +40,11 @@ func (d Room) Show() {
 // Liste
 type RoomTable = DataTable[Room]
 
+// ShowExtra prints the value of the items in the room.
+func (d Room) ShowExtra(ownid uint32) {
+	showContainerValue(Ref{"rooms", ownid})
+}
+
 // GetRoomNamesforWarehouse returns rooms belonging to a warehouse.
 func GetRoomNamesforWarehouse(rt *RoomTable, wid uint32) []Listentry {
 	var names []Listentry
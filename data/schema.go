@@ -0,0 +1,125 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// SchemaVersion is the version of the document layout written by Save.
+// Files without a version are version 0.
+const SchemaVersion = 8
+
+// Migration upgrades a document from version From to From+1.
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(doc Document) error
+}
+
+// migrations lists the upgrades in order, migrations[i] upgrades version i.
+var migrations = []Migration{
+	{From: 0, Description: "store the deletion time of deleted records", Apply: migrateDeletedAt},
+	{From: 1, Description: "add the amount ledger of items", Apply: addedFields},
+	{From: 2, Description: "add the loans of items", Apply: addedFields},
+	{From: 3, Description: "add the minimum amounts of items", Apply: addedFields},
+	{From: 4, Description: "add the expiry dates of items", Apply: addedFields},
+	{From: 5, Description: "add the purchase details of items", Apply: addedFields},
+	{From: 6, Description: "add the warranty dates and receipts of items", Apply: addedFields},
+	{From: 7, Description: "add the saved views", Apply: addedFields},
+}
+
+// ErrNewerSchema is returned when a file was written by a newer lgrt.
+var ErrNewerSchema = errors.New("the database was written by a newer version of lgrt")
+
+// documentVersion returns the schema version of a document.
+func documentVersion(doc Document) (int, error) {
+	raw, ok := doc["schemaVersion"]
+	if !ok {
+		return 0, nil
+	}
+	var version int
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return 0, fmt.Errorf("schema version: %w", err)
+	}
+	return version, nil
+}
+
+// migrate upgrades a document to SchemaVersion and returns the version it had.
+func migrate(doc Document) (int, error) {
+	version, err := documentVersion(doc)
+	if err != nil {
+		return 0, err
+	}
+	if version > SchemaVersion {
+		return version, fmt.Errorf("%w (schema version %d, supported %d)", ErrNewerSchema, version, SchemaVersion)
+	}
+	for _, m := range migrations[version:] {
+		if err := m.Apply(doc); err != nil {
+			return version, fmt.Errorf("migrate from schema version %d: %w", m.From, err)
+		}
+	}
+	doc["schemaVersion"] = json.RawMessage(fmt.Sprint(SchemaVersion))
+	return version, nil
+}
+
+// SchemaBackupPath returns where the file is kept before migrating it from version.
+func SchemaBackupPath(path string, version int) string {
+	return fmt.Sprintf("%s.schema%d", path, version)
+}
+
+// backupBeforeMigration copies the unmigrated file, an existing copy is kept.
+func backupBeforeMigration(path string, version int) error {
+	backup := SchemaBackupPath(path, version)
+	if _, err := os.Stat(backup); err == nil {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(backup, content, 0644)
+}
+
+// migrateDeletedAt sets deletedAt of deleted records to their last update,
+// which is when they were deleted unless edited afterwards.
+func migrateDeletedAt(doc Document) error {
+	for name, raw := range doc {
+		if !isTable(raw) {
+			continue
+		}
+		var records []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &records); err != nil {
+			return fmt.Errorf("table %s: %w", name, err)
+		}
+		changed := false
+		for _, record := range records {
+			var deleted bool
+			if json.Unmarshal(record["deleted"], &deleted) != nil || !deleted {
+				continue
+			}
+			if _, ok := record["deletedAt"]; !ok && record["updated"] != nil {
+				record["deletedAt"] = record["updated"]
+				changed = true
+			}
+		}
+		if !changed {
+			// keep the original bytes so load errors can still be located in the file
+			continue
+		}
+		migrated, err := json.Marshal(records)
+		if err != nil {
+			return fmt.Errorf("table %s: %w", name, err)
+		}
+		doc[name] = migrated
+	}
+	return nil
+}
+
+// addedFields migrates a version that only added optional fields. Older files
+// decode with their zero values, the version only keeps older lgrt from
+// writing the file and dropping the new fields.
+func addedFields(doc Document) error {
+	return nil
+}
@@ -0,0 +1,318 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SearchIndex is an inverted index of the word stems in the items. It is
+// stored next to the database and answers the plain words of a search query.
+type SearchIndex struct {
+	// Revision is the database revision the index describes
+	Revision uint64 `json:"revision"`
+	// Terms maps word stems to the sorted ids of the items containing them
+	Terms map[string][]uint32 `json:"terms"`
+	// Docs holds the Updated time of every indexed item
+	Docs map[uint32]int64 `json:"docs"`
+
+	// sorted lists the terms for substring lookups, nil after a change
+	sorted []string
+}
+
+// searchIndex caches the index of the database it was built for.
+var searchIndex struct {
+	db *Database
+	ix *SearchIndex
+}
+
+// stemSuffixes are removed from words, longest first. They cover the common
+// English and German plural and derivation endings.
+var stemSuffixes = []string{"ungen", "heit", "keit", "lich", "isch", "ung", "ing", "ern", "ies", "en", "er", "em", "es", "ed", "ly", "e", "s", "n"}
+
+// umlauts folds German special characters, so "Säge" finds "Sage" and "Saege".
+var umlauts = strings.NewReplacer("ä", "a", "ö", "o", "ü", "u", "ß", "ss", "ae", "a", "oe", "o", "ue", "u")
+
+// IndexPath returns the location of the search index file.
+func IndexPath() (string, error) {
+	path, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return path + ".index", nil
+}
+
+// Stem reduces a lowercase word to its stem by folding umlauts and removing
+// up to two endings, keeping at least three characters.
+func Stem(word string) string {
+	word = umlauts.Replace(word)
+	for pass := 0; pass < 2; pass++ {
+		stripped := false
+		for _, suffix := range stemSuffixes {
+			stem, found := strings.CutSuffix(word, suffix)
+			if found && len([]rune(stem)) >= 3 {
+				word = stem
+				if suffix == "ies" {
+					word += "y"
+				}
+				stripped = true
+				break
+			}
+		}
+		if !stripped {
+			break
+		}
+	}
+	return word
+}
+
+// newSearchIndex returns an empty index for a database revision.
+func newSearchIndex(revision uint64) *SearchIndex {
+	return &SearchIndex{Revision: revision, Terms: map[string][]uint32{}, Docs: map[uint32]int64{}}
+}
+
+// readSearchIndex reads the index file, nil if it is missing or unreadable.
+func readSearchIndex() *SearchIndex {
+	path, err := IndexPath()
+	if err != nil {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	ix := newSearchIndex(0)
+	if json.Unmarshal(content, ix) != nil || ix.Terms == nil || ix.Docs == nil {
+		return nil
+	}
+	return ix
+}
+
+// write stores the index next to the database.
+func (ix *SearchIndex) write() error {
+	path, err := IndexPath()
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(ix)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, content, 0644)
+}
+
+// indexedText returns the text of an item that is indexed.
+func indexedText(set Dataset[Item]) string {
+	return strings.Join([]string{set.Name, set.Description, set.Data.Location, set.Data.Condition,
+		set.Data.Vendor, set.Data.Serial, GetTagList(set.Tags)}, " ")
+}
+
+// add indexes an item.
+func (ix *SearchIndex) add(set Dataset[Item]) {
+	list, _ := words(indexedText(set))
+	seen := map[string]bool{}
+	for _, word := range list {
+		stem := Stem(word)
+		if seen[stem] {
+			continue
+		}
+		seen[stem] = true
+		ids := ix.Terms[stem]
+		if i, found := slices.BinarySearch(ids, set.ID); !found {
+			ix.Terms[stem] = slices.Insert(ids, i, set.ID)
+		}
+	}
+	ix.Docs[set.ID] = set.Updated
+	ix.sorted = nil
+}
+
+// remove drops items from the index.
+func (ix *SearchIndex) remove(ids map[uint32]bool) {
+	for term, list := range ix.Terms {
+		list = slices.DeleteFunc(list, func(id uint32) bool { return ids[id] })
+		if len(list) == 0 {
+			delete(ix.Terms, term)
+		} else {
+			ix.Terms[term] = list
+		}
+	}
+	for id := range ids {
+		delete(ix.Docs, id)
+	}
+	ix.sorted = nil
+}
+
+// refresh indexes the items that were added or changed since they were
+// indexed, and those in force, and drops deleted ones. It reports whether
+// the index changed.
+func (ix *SearchIndex) refresh(db *Database, force map[uint32]bool) bool {
+	stale := map[uint32]bool{}
+	live := 0
+	for _, set := range db.Items {
+		if set.Deleted {
+			continue
+		}
+		live++
+		if updated, ok := ix.Docs[set.ID]; !ok || updated != set.Updated || force[set.ID] {
+			stale[set.ID] = true
+		}
+	}
+	if len(stale) == 0 && len(ix.Docs) == live {
+		return false
+	}
+	ids := map[uint32]bool{}
+	for _, set := range db.Items {
+		if !set.Deleted {
+			ids[set.ID] = true
+		}
+	}
+	indexed := map[uint32]bool{}
+	for id := range ix.Docs {
+		if !ids[id] || stale[id] {
+			stale[id] = true
+			indexed[id] = true
+		}
+	}
+	if len(indexed) > 0 {
+		ix.remove(indexed)
+	}
+	for _, set := range db.Items {
+		if !set.Deleted && stale[set.ID] {
+			ix.add(set)
+		}
+	}
+	return true
+}
+
+// SearchIndex returns the search index of the database, read from disk. An
+// index of another revision is rebuilt and written. Changes saved by this
+// process are added by UpdateIndex, so the items are not looked at as long
+// as the revision matches.
+func (db *Database) SearchIndex() *SearchIndex {
+	ix := searchIndex.ix
+	if searchIndex.db != db || ix == nil || ix.Revision != db.Revision {
+		ix = readSearchIndex()
+		if ix == nil || ix.Revision != db.Revision {
+			ix = newSearchIndex(db.Revision)
+			ix.refresh(db, nil)
+			// the index is only a cache, the next search rebuilds it if this fails
+			_ = db.storeIndex(ix)
+		}
+	}
+	searchIndex.db, searchIndex.ix = db, ix
+	return ix
+}
+
+// storeIndex writes the index while holding the database lock. It is skipped
+// if the file was saved again since the revision the index describes.
+func (db *Database) storeIndex(ix *SearchIndex) error {
+	s, err := currentStore()
+	if err != nil {
+		return err
+	}
+	lock, err := LockPath(s.Path())
+	if err != nil {
+		return fmt.Errorf("lock database: %w", err)
+	}
+	defer lock.Unlock()
+	if stored, err := s.Revision(); err != nil || stored != ix.Revision {
+		return err
+	}
+	return ix.write()
+}
+
+// UpdateIndex updates the search index after a save that changed refs. Items
+// are indexed with their tag names, so a changed tag re-indexes all items.
+func (db *Database) UpdateIndex(refs []Ref) error {
+	ix := searchIndex.ix
+	if searchIndex.db != db || ix == nil {
+		ix = readSearchIndex()
+	}
+	if ix == nil || ix.Revision != db.Revision-1 && ix.Revision != db.Revision {
+		ix = newSearchIndex(db.Revision)
+	}
+	force := map[uint32]bool{}
+	for _, ref := range refs {
+		switch ref.Table {
+		case "items":
+			force[ref.ID] = true
+		case "tags":
+			for _, set := range db.Items {
+				force[set.ID] = true
+			}
+		}
+	}
+	ix.Revision = db.Revision
+	ix.refresh(db, force)
+	searchIndex.db, searchIndex.ix = db, ix
+	return db.storeIndex(ix)
+}
+
+// Lookup returns the ids of the items containing every word of text. Words
+// match by stem anywhere in an indexed word, "schraub" and "zieher" find
+// "Schraubenzieher".
+func (ix *SearchIndex) Lookup(text string) map[uint32]bool {
+	list, _ := words(text)
+	matches := make([][]string, len(list))
+	counts := make([]int, len(list))
+	for i, word := range list {
+		matches[i] = ix.containing(Stem(word))
+		for _, term := range matches[i] {
+			counts[i] += len(ix.Terms[term])
+		}
+	}
+	// start with the rarest word and drop the ids the others lack
+	order := make([]int, len(list))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return counts[order[a]] < counts[order[b]] })
+	result := map[uint32]bool{}
+	for n, i := range order {
+		if n == 0 {
+			for _, term := range matches[i] {
+				for _, id := range ix.Terms[term] {
+					result[id] = true
+				}
+			}
+			continue
+		}
+		for id := range result {
+			if !ix.contains(matches[i], id) {
+				delete(result, id)
+			}
+		}
+	}
+	return result
+}
+
+// contains reports whether one of the terms occurs in an item.
+func (ix *SearchIndex) contains(terms []string, id uint32) bool {
+	for _, term := range terms {
+		if _, found := slices.BinarySearch(ix.Terms[term], id); found {
+			return true
+		}
+	}
+	return false
+}
+
+// containing returns the terms that contain part.
+func (ix *SearchIndex) containing(part string) []string {
+	if ix.sorted == nil {
+		ix.sorted = make([]string, 0, len(ix.Terms))
+		for term := range ix.Terms {
+			ix.sorted = append(ix.sorted, term)
+		}
+		sort.Strings(ix.sorted)
+	}
+	var terms []string
+	for _, term := range ix.sorted {
+		if strings.Contains(term, part) {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
@@ -18,6 +18,18 @@ func (d Shelf) GetTableRow(ownid uint32) string {
 	return fmt.Sprintf("%-20s %-20s", roomName, whName)
 }
 
+// GetColumns returns the shelf fields with resolved container names.
+func (d Shelf) GetColumns(ownid uint32) []Column {
+	warehouseId := GetWarehouseIdforRoom(d.RoomId)
+	return []Column{
+		{"location", d.Location},
+		{"roomId", d.RoomId},
+		{"room", nameById(&Db.Rooms, d.RoomId)},
+		{"warehouseId", warehouseId},
+		{"warehouse", nameById(&Db.Warehouses, warehouseId)},
+	}
+}
+
 // GetTableHeader returns the shelf table header.
 func (d Shelf) GetTableHeader() string {
 	return fmt.Sprintf("%-20s %-20s%s", "Room", "Warehouse", terminal.ResetColor())
@@ -29,6 +41,11 @@ func (d Shelf) Show() {
 	fmt.Printf("%s %s\n", terminal.GetLabelText("Room"), GetPrintNameById(&Db.Rooms, d.RoomId, 999))
 }
 
+// ShowExtra prints the value of the items in the shelf.
+func (d Shelf) ShowExtra(ownid uint32) {
+	showContainerValue(Ref{"shelves", ownid})
+}
+
 type ShelfTable = DataTable[Shelf]
 
 // Checks if a shelf exists in the current warehouse with a given room
@@ -0,0 +1,318 @@
+package data
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS tables (
+	name TEXT PRIMARY KEY
+);
+CREATE TABLE IF NOT EXISTS records (
+	tbl  TEXT NOT NULL,
+	id   INTEGER NOT NULL,
+	body TEXT NOT NULL,
+	PRIMARY KEY (tbl, id)
+);`
+
+// SQLiteStore keeps every record in its own row, so Save only writes
+// the records that changed since the last Load or Save.
+type SQLiteStore struct {
+	path string
+	db   *sql.DB
+	// known holds a hash of every record as last read or written
+	known map[string]map[uint32]uint64
+	// tx is the transaction of a running Update, committed lists the
+	// updates of known to make once it is committed
+	tx        *sql.Tx
+	committed []func()
+}
+
+// OpenSQLiteStore opens or creates the SQLite database at path.
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	return &SQLiteStore{path: path, db: db, known: map[string]map[uint32]uint64{}}, nil
+}
+
+// Path returns the database file name.
+func (s *SQLiteStore) Path() string {
+	return s.path
+}
+
+// recordHash returns the hash used to detect changed records.
+func recordHash(record json.RawMessage) uint64 {
+	h := fnv.New64a()
+	h.Write(record)
+	return h.Sum64()
+}
+
+// Load reads all values and tables into a document.
+// An empty database reports os.ErrNotExist like a missing JSON file.
+func (s *SQLiteStore) Load() (Document, error) {
+	doc := Document{}
+	rows, err := s.db.Query(`SELECT key, value FROM meta`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		doc[key] = json.RawMessage(value)
+	}
+	rows.Close()
+
+	var tables []string
+	rows, err = s.db.Query(`SELECT name FROM tables`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+
+	if len(doc) == 0 && len(tables) == 0 {
+		return nil, fmt.Errorf("%s: %w", s.path, os.ErrNotExist)
+	}
+	s.known = map[string]map[uint32]uint64{}
+	for _, table := range tables {
+		records := []json.RawMessage{}
+		known := map[uint32]uint64{}
+		err := s.query(table, func(id uint32, record json.RawMessage) bool {
+			records = append(records, record)
+			known[id] = recordHash(record)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		raw, err := json.Marshal(records)
+		if err != nil {
+			return nil, err
+		}
+		doc[table] = raw
+		s.known[table] = known
+	}
+	return doc, nil
+}
+
+// Save writes changed records and values in a single transaction.
+func (s *SQLiteStore) Save(doc Document) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	known := map[string]map[uint32]uint64{}
+	for key, raw := range doc {
+		if !isTable(raw) {
+			if _, err := tx.Exec(`INSERT OR REPLACE INTO meta (key, value) VALUES (?, ?)`, key, string(raw)); err != nil {
+				tx.Rollback()
+				return err
+			}
+			continue
+		}
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO tables (name) VALUES (?)`, key); err != nil {
+			tx.Rollback()
+			return err
+		}
+		tableKnown, err := s.saveTable(tx, key, raw)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("save %s: %w", key, err)
+		}
+		known[key] = tableKnown
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.known = known
+	return nil
+}
+
+// saveTable upserts changed records and deletes the ones no longer present.
+func (s *SQLiteStore) saveTable(tx *sql.Tx, table string, raw json.RawMessage) (map[uint32]uint64, error) {
+	var records []json.RawMessage
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return nil, err
+	}
+	previous := s.known[table]
+	known := make(map[uint32]uint64, len(records))
+	for _, record := range records {
+		id, err := recordId(record)
+		if err != nil {
+			return nil, err
+		}
+		hash := recordHash(record)
+		known[id] = hash
+		if old, ok := previous[id]; ok && old == hash {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO records (tbl, id, body) VALUES (?, ?, ?)`, table, id, string(record)); err != nil {
+			return nil, err
+		}
+	}
+	for id := range previous {
+		if _, ok := known[id]; ok {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM records WHERE tbl = ? AND id = ?`, table, id); err != nil {
+			return nil, err
+		}
+	}
+	return known, nil
+}
+
+// Get returns a single record of a table.
+func (s *SQLiteStore) Get(table string, id uint32) (json.RawMessage, bool, error) {
+	var body string
+	err := s.db.QueryRow(`SELECT body FROM records WHERE tbl = ? AND id = ?`, table, id).Scan(&body)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return json.RawMessage(body), true, nil
+}
+
+// Update runs fn in a single transaction. Put and Delete called by fn
+// join it, so either all of their changes are written or none.
+func (s *SQLiteStore) Update(fn func() error) error {
+	if s.tx != nil {
+		return fn()
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	s.tx, s.committed = tx, nil
+	err = fn()
+	s.tx = nil
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, update := range s.committed {
+		update()
+	}
+	s.committed = nil
+	return nil
+}
+
+// Put inserts or replaces a single record of a table.
+func (s *SQLiteStore) Put(table string, id uint32, record json.RawMessage) error {
+	return s.Update(func() error {
+		if _, err := s.tx.Exec(`INSERT OR IGNORE INTO tables (name) VALUES (?)`, table); err != nil {
+			return err
+		}
+		if _, err := s.tx.Exec(`INSERT OR REPLACE INTO records (tbl, id, body) VALUES (?, ?, ?)`, table, id, string(record)); err != nil {
+			return err
+		}
+		s.committed = append(s.committed, func() {
+			if s.known[table] != nil {
+				s.known[table][id] = recordHash(record)
+			}
+		})
+		return nil
+	})
+}
+
+// Delete physically removes a single record of a table.
+func (s *SQLiteStore) Delete(table string, id uint32) error {
+	return s.Update(func() error {
+		if _, err := s.tx.Exec(`DELETE FROM records WHERE tbl = ? AND id = ?`, table, id); err != nil {
+			return err
+		}
+		s.committed = append(s.committed, func() {
+			delete(s.known[table], id)
+		})
+		return nil
+	})
+}
+
+// PutValue stores a database value that is not a table, like the revision.
+func (s *SQLiteStore) PutValue(key string, value json.RawMessage) error {
+	return s.Update(func() error {
+		_, err := s.tx.Exec(`INSERT OR REPLACE INTO meta (key, value) VALUES (?, ?)`, key, string(value))
+		return err
+	})
+}
+
+// Query calls fn for every record of a table until fn returns false.
+func (s *SQLiteStore) Query(table string, fn func(record json.RawMessage) bool) error {
+	return s.query(table, func(_ uint32, record json.RawMessage) bool {
+		return fn(record)
+	})
+}
+
+// query iterates the records of a table in id order.
+func (s *SQLiteStore) query(table string, fn func(id uint32, record json.RawMessage) bool) error {
+	rows, err := s.db.Query(`SELECT id, body FROM records WHERE tbl = ? ORDER BY id`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uint32
+		var body string
+		if err := rows.Scan(&id, &body); err != nil {
+			return err
+		}
+		if !fn(id, json.RawMessage(body)) {
+			break
+		}
+	}
+	return rows.Err()
+}
+
+// Revision returns the revision counter stored in the meta table.
+func (s *SQLiteStore) Revision() (uint64, error) {
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM meta WHERE key = 'revision'`).Scan(&value)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var revision uint64
+	err = json.Unmarshal([]byte(value), &revision)
+	return revision, err
+}
+
+// Close closes the database connection.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
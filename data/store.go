@@ -0,0 +1,191 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Document is the database split into its top-level JSON values.
+// Tables are JSON arrays of records that carry an "id".
+type Document map[string]json.RawMessage
+
+// Store persists a database. Tables are addressed by their JSON name,
+// records are passed as raw JSON.
+type Store interface {
+	// Load returns the stored document or an error wrapping os.ErrNotExist.
+	Load() (Document, error)
+	// Save replaces the stored document.
+	Save(doc Document) error
+	// Get returns a single record of a table.
+	Get(table string, id uint32) (json.RawMessage, bool, error)
+	// Put inserts or replaces a single record of a table.
+	Put(table string, id uint32, record json.RawMessage) error
+	// Delete physically removes a single record of a table.
+	Delete(table string, id uint32) error
+	// Query calls fn for every record of a table until fn returns false.
+	Query(table string, fn func(record json.RawMessage) bool) error
+	// Revision returns the revision counter of the stored database.
+	Revision() (uint64, error)
+	// Path returns the file backing the store.
+	Path() string
+	Close() error
+}
+
+// IsSQLitePath reports whether a database file name selects the SQLite backend.
+func IsSQLitePath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".sqlite", ".sqlite3", ".db":
+		return true
+	}
+	return false
+}
+
+// OpenStore opens the backend matching the file extension of path.
+func OpenStore(path string) (Store, error) {
+	if IsSQLitePath(path) {
+		return OpenSQLiteStore(path)
+	}
+	return NewJSONStore(path), nil
+}
+
+var openStore Store
+
+// currentStore returns the store for Path, reopening it when the path changed.
+func currentStore() (Store, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	if openStore != nil && openStore.Path() == path {
+		return openStore, nil
+	}
+	CloseStore()
+	s, err := OpenStore(path)
+	if err != nil {
+		return nil, err
+	}
+	openStore = s
+	return s, nil
+}
+
+// CloseStore closes the store opened by Save or Load.
+func CloseStore() {
+	if openStore != nil {
+		_ = openStore.Close()
+		openStore = nil
+	}
+}
+
+// fields maps the JSON names of the database fields to pointers to them.
+func (db *Database) fields() map[string]any {
+	fields := map[string]any{}
+	v := reflect.ValueOf(db).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || !v.Type().Field(i).IsExported() {
+			continue
+		}
+		fields[name] = v.Field(i).Addr().Interface()
+	}
+	return fields
+}
+
+// encode converts the database to a document.
+func (db *Database) encode() (Document, error) {
+	doc := Document{}
+	for name, field := range db.fields() {
+		raw, err := json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", name, err)
+		}
+		doc[name] = raw
+	}
+	return doc, nil
+}
+
+// TableError reports a table of a document that could not be decoded.
+type TableError struct {
+	Table string
+	Err   error
+}
+
+// Error returns the error with the table name.
+func (e *TableError) Error() string {
+	return fmt.Sprintf("table %s: %v", e.Table, e.Err)
+}
+
+// Unwrap returns the underlying decoder error.
+func (e *TableError) Unwrap() error {
+	return e.Err
+}
+
+// decode fills the database from a document, unknown keys are ignored.
+func (db *Database) decode(doc Document) error {
+	for name, field := range db.fields() {
+		raw, ok := doc[name]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(raw, field); err != nil {
+			return &TableError{Table: name, Err: err}
+		}
+	}
+	return nil
+}
+
+// loadFrom replaces the database with the content of a store.
+// The database is left untouched when the document cannot be decoded.
+func (db *Database) loadFrom(s Store) error {
+	doc, err := s.Load()
+	if err != nil {
+		return err
+	}
+	version, err := migrate(doc)
+	if errors.Is(err, ErrNewerSchema) {
+		return fmt.Errorf("%s: %w", s.Path(), err)
+	}
+	if err != nil {
+		return &LoadError{Path: s.Path(), Err: err}
+	}
+	loaded := NewDatabase()
+	if err := loaded.decode(doc); err != nil {
+		var tableErr *TableError
+		if js, ok := s.(*JSONStore); ok && errors.As(err, &tableErr) {
+			return js.locate(doc, tableErr)
+		}
+		return &LoadError{Path: s.Path(), Err: err}
+	}
+	loaded.loadedRevision = loaded.Revision
+	loaded.loadedSchema = version
+	*db = *loaded
+	return nil
+}
+
+// isTable reports whether a raw value is an array of records with ids.
+func isTable(raw json.RawMessage) bool {
+	var records []struct {
+		ID *uint32 `json:"id"`
+	}
+	if json.Unmarshal(raw, &records) != nil || records == nil {
+		return false
+	}
+	for _, r := range records {
+		if r.ID == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// recordId returns the id of a raw record.
+func recordId(record json.RawMessage) (uint32, error) {
+	var r struct {
+		ID uint32 `json:"id"`
+	}
+	err := json.Unmarshal(record, &r)
+	return r.ID, err
+}
@@ -0,0 +1,182 @@
+package data
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+// storeRoundTrip saves a small database to a store and loads it back.
+func storeRoundTrip(t *testing.T, s Store) {
+	t.Helper()
+	resetDb()
+	wh := NewDataset[Warehouse]("Home", Warehouse{Location: "Street 1"})
+	Db.Warehouses.Add(wh)
+	Db.CurrentWarehouse = wh.ID
+	Db.Items.Add(NewDataset[Item]("Hammer", Item{Amount: 2}))
+	doc, err := Db.encode()
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if err := s.Save(doc); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	db2 := NewDatabase()
+	if err := db2.loadFrom(s); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if db2.CurrentWarehouse != wh.ID || len(db2.Warehouses) != 1 || db2.Warehouses[0].Data.Location != "Street 1" {
+		t.Fatalf("warehouses not restored: %+v", db2)
+	}
+	if len(db2.Items) != 1 || db2.Items[0].Data.Amount != 2 {
+		t.Fatalf("items not restored: %+v", db2.Items)
+	}
+	if db2.Rooms == nil || len(db2.Rooms) != 0 {
+		t.Fatalf("expected empty rooms table, got %+v", db2.Rooms)
+	}
+}
+
+// storeRecords exercises the per-record operations of a store.
+func storeRecords(t *testing.T, s Store) {
+	t.Helper()
+	record := json.RawMessage(`{"id":42,"name":"Saw","data":{"amount":1}}`)
+	if err := s.Put("items", 42, record); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	got, ok, err := s.Get("items", 42)
+	if err != nil || !ok {
+		t.Fatalf("get: ok=%v err=%v", ok, err)
+	}
+	var set Dataset[Item]
+	if err := json.Unmarshal(got, &set); err != nil || set.Name != "Saw" {
+		t.Fatalf("unexpected record %s (%v)", got, err)
+	}
+	count := 0
+	if err := s.Query("items", func(json.RawMessage) bool { count++; return true }); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 items, got %d", count)
+	}
+	if err := s.Delete("items", 42); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, ok, _ := s.Get("items", 42); ok {
+		t.Fatalf("expected record to be deleted")
+	}
+}
+
+// TestJSONStore verifies the JSON file backend.
+func TestJSONStore(t *testing.T) {
+	s := NewJSONStore(filepath.Join(t.TempDir(), "lgrtdata.json"))
+	storeRoundTrip(t, s)
+	storeRecords(t, s)
+}
+
+// TestSQLiteStore verifies the SQLite backend.
+func TestSQLiteStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lgrtdata.sqlite")
+	s, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer s.Close()
+	storeRoundTrip(t, s)
+	storeRecords(t, s)
+}
+
+// TestSQLiteStoreIncremental verifies that only changed records are written
+// and that removed records are deleted.
+func TestSQLiteStoreIncremental(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lgrtdata.sqlite")
+	resetDb()
+	SetPath(path)
+	defer SetPath("")
+	defer CloseStore()
+
+	Db.Items.Add(NewDataset[Item]("Keep", Item{}))
+	Db.Items.Add(NewDataset[Item]("Drop", Item{}))
+	if err := Db.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	// a record written behind the store's back must survive a save
+	// that does not touch it
+	s := openStore.(*SQLiteStore)
+	if _, err := s.db.Exec(`UPDATE records SET body = ? WHERE tbl = 'items' AND id = ?`,
+		`{"id":1,"name":"Changed"}`, Db.Items[0].ID); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	Db.Items = Db.Items[:1]
+	if err := Db.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	db2 := NewDatabase()
+	if err := db2.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(db2.Items) != 1 || db2.Items[0].Name != "Changed" {
+		t.Fatalf("unexpected items after incremental save: %+v", db2.Items)
+	}
+}
+
+// TestSQLiteSaveChanges verifies that SaveChanges writes only the given
+// records and values, deletes missing ones and bumps the revision.
+func TestSQLiteSaveChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lgrtdata.sqlite")
+	resetDb()
+	SetPath(path)
+	defer SetPath("")
+	defer CloseStore()
+
+	Db.Items.Add(NewDataset[Item]("Saw", Item{Amount: 1}))
+	Db.Items.Add(NewDataset[Item]("Drill", Item{Amount: 1}))
+	Db.Items.Add(NewDataset[Item]("Hammer", Item{Amount: 1}))
+	if err := Db.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	saw, drill, hammer := Db.Items[0].ID, Db.Items[1].ID, Db.Items[2].ID
+	Db.Items[0].Data.Amount = 5
+	Db.Items[1].Data.Amount = 7
+	Db.Items.removeRecord(hammer)
+	Db.CurrentWarehouse = 9
+	refs := []Ref{{Table: "items", ID: saw}, {Table: "items", ID: hammer}, {Table: "currentWarehouseid"}}
+	if err := Db.SaveChanges(refs, nil); err != nil {
+		t.Fatalf("save records: %v", err)
+	}
+
+	db2 := NewDatabase()
+	if err := db2.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if db2.Revision != 2 || db2.CurrentWarehouse != 9 {
+		t.Fatalf("unexpected revision %d or warehouse %d", db2.Revision, db2.CurrentWarehouse)
+	}
+	amounts := map[uint32]int{}
+	for _, set := range db2.Items {
+		amounts[set.ID] = set.Data.Amount
+	}
+	// the drill was not given, its change is not written
+	if len(amounts) != 2 || amounts[saw] != 5 || amounts[drill] != 1 {
+		t.Fatalf("unexpected items: %v", amounts)
+	}
+}
+
+// TestJSONStoreTypeErrorPosition verifies file positions for table errors.
+func TestJSONStoreTypeErrorPosition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lgrtdata.json")
+	content := "{\"warehouses\":[],\n\"rooms\":[{\"id\":\"x\"}]}"
+	if err := writeFileAtomic(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	err := NewDatabase().loadFrom(NewJSONStore(path))
+	loadErr, ok := err.(*LoadError)
+	if !ok {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	if loadErr.Line != 2 {
+		t.Fatalf("expected error on line 2, got %d", loadErr.Line)
+	}
+}
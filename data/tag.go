@@ -14,6 +14,11 @@ func (d Tag) GetTableRow(ownid uint32) string {
 	return fmt.Sprintf("%4d", Db.CountTagOccurance(ownid))
 }
 
+// GetColumns returns the number of uses of the tag.
+func (d Tag) GetColumns(ownid uint32) []Column {
+	return []Column{{"uses", Db.CountTagOccurance(ownid)}}
+}
+
 // GetTableHeader returns the tag table header.
 func (d Tag) GetTableHeader() string {
 	return fmt.Sprintf("%-15s%s", "uses", terminal.ResetColor())
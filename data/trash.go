@@ -0,0 +1,292 @@
+package data
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ref points at a record by the JSON name of its table and its id.
+type Ref struct {
+	Table string
+	ID    uint32
+}
+
+// RecordInfo describes a record of any table, deleted records included.
+type RecordInfo struct {
+	Ref
+	Type      string
+	Name      string
+	Deleted   bool
+	DeletedAt int64
+}
+
+// TableOrder lists the tables from the top of the hierarchy down.
+var TableOrder = []string{"warehouses", "rooms", "shelves", "boxes", "items", "categories", "tags"}
+
+// DeletionTime returns when the dataset was deleted. Records deleted
+// before the time was stored fall back to their last update.
+func (d Dataset[T]) DeletionTime() int64 {
+	if d.DeletedAt != 0 {
+		return d.DeletedAt
+	}
+	return d.Updated
+}
+
+// containerRef returns the container of a dataset in the hierarchy.
+// ok is false for types that are not stored in a container.
+func containerRef(data any) (ref Ref, ok bool) {
+	switch d := data.(type) {
+	case Room:
+		return Ref{"warehouses", d.WarehouseId}, true
+	case Shelf:
+		return Ref{"rooms", d.RoomId}, true
+	case Box:
+		return Ref{"shelves", d.ShelfId}, true
+	case Item:
+		return Ref{"boxes", d.BoxId}, true
+	}
+	return Ref{}, false
+}
+
+// parentRefs returns the records the data of a dataset refers to.
+// id 0 means no reference, e.g. an item without category.
+func parentRefs(data any) []Ref {
+	var refs []Ref
+	if container, ok := containerRef(data); ok && container.ID != 0 {
+		refs = append(refs, container)
+	}
+	if item, ok := data.(Item); ok && item.CategoryId != 0 {
+		refs = append(refs, Ref{"categories", item.CategoryId})
+	}
+	return refs
+}
+
+// info describes the dataset at idx, table is the JSON name of dt.
+func (dt *DataTable[T]) info(idx int, table string) RecordInfo {
+	set := (*dt)[idx]
+	t := strings.Split(fmt.Sprintf("%T", *new(T)), ".")
+	info := RecordInfo{Ref: Ref{Table: table, ID: set.ID}, Type: t[1], Name: set.Name, Deleted: set.Deleted}
+	if set.Deleted {
+		info.DeletedAt = set.DeletionTime()
+	}
+	return info
+}
+
+// record describes the dataset with the given id.
+func (dt *DataTable[T]) record(id uint32) (RecordInfo, bool) {
+	idx := dt.GetIdxAny(id)
+	if idx < 0 {
+		return RecordInfo{}, false
+	}
+	return dt.info(idx, Db.TableName(dt)), true
+}
+
+// trash describes all deleted datasets.
+func (dt *DataTable[T]) trash() []RecordInfo {
+	var list []RecordInfo
+	table := Db.TableName(dt)
+	for i, set := range *dt {
+		if set.Deleted {
+			list = append(list, dt.info(i, table))
+		}
+	}
+	return list
+}
+
+// restore clears the deleted flag of a dataset.
+func (dt *DataTable[T]) restore(id uint32) {
+	idx := dt.GetIdxAny(id)
+	if idx < 0 || !(*dt)[idx].Deleted {
+		return
+	}
+	(*dt)[idx].Deleted = false
+	(*dt)[idx].DeletedAt = 0
+	(*dt)[idx].Updated = time.Now().Unix()
+}
+
+// parents returns the records a dataset refers to.
+func (dt *DataTable[T]) parents(id uint32) []Ref {
+	idx := dt.GetIdxAny(id)
+	if idx < 0 {
+		return nil
+	}
+	return parentRefs((*dt)[idx].Data)
+}
+
+// children returns the live datasets that refer to parent.
+func (dt *DataTable[T]) children(parent Ref) []Ref {
+	var refs []Ref
+	table := Db.TableName(dt)
+	for _, set := range *dt {
+		if !set.Deleted && slices.Contains(parentRefs(set.Data), parent) {
+			refs = append(refs, Ref{table, set.ID})
+		}
+	}
+	return refs
+}
+
+// tagged returns all datasets carrying a tag, deleted ones included.
+func (dt *DataTable[T]) tagged(tagid uint32) []Ref {
+	var refs []Ref
+	table := Db.TableName(dt)
+	for _, set := range *dt {
+		if slices.Contains(set.Tags, tagid) {
+			refs = append(refs, Ref{table, set.ID})
+		}
+	}
+	return refs
+}
+
+// untag removes a tag from a dataset.
+func (dt *DataTable[T]) untag(id uint32, tagid uint32) {
+	idx := dt.GetIdxAny(id)
+	if idx < 0 {
+		return
+	}
+	(*dt)[idx].Tags = slices.DeleteFunc((*dt)[idx].Tags, func(t uint32) bool { return t == tagid })
+}
+
+// softDelete marks a dataset as deleted.
+func (dt *DataTable[T]) softDelete(id uint32) {
+	dt.Delete(id)
+}
+
+// reparent points the reference of a dataset that refers to from at the record with id to.
+func (dt *DataTable[T]) reparent(id uint32, from Ref, to uint32) {
+	idx := dt.GetIdxAny(id)
+	if idx < 0 {
+		return
+	}
+	var field *uint32
+	table := ""
+	switch d := any(&(*dt)[idx].Data).(type) {
+	case *Room:
+		field, table = &d.WarehouseId, "warehouses"
+	case *Shelf:
+		field, table = &d.RoomId, "rooms"
+	case *Box:
+		field, table = &d.ShelfId, "shelves"
+	case *Item:
+		field, table = &d.BoxId, "boxes"
+		if from.Table == "categories" {
+			field, table = &d.CategoryId, "categories"
+		}
+	}
+	if field == nil || table != from.Table || *field != from.ID {
+		return
+	}
+	*field = to
+	(*dt)[idx].Updated = time.Now().Unix()
+}
+
+// Record describes a record, deleted records included.
+func (db *Database) Record(ref Ref) (RecordInfo, bool) {
+	tbl, err := db.table(ref.Table)
+	if err != nil {
+		return RecordInfo{}, false
+	}
+	return tbl.record(ref.ID)
+}
+
+// FindRecord looks up an id in all tables. A deleted record is only
+// returned if no live record has the id.
+func (db *Database) FindRecord(id uint32) (RecordInfo, bool) {
+	var found RecordInfo
+	ok := false
+	for _, name := range TableOrder {
+		info, exists := db.Record(Ref{name, id})
+		if exists && (!ok || found.Deleted && !info.Deleted) {
+			found, ok = info, true
+		}
+	}
+	return found, ok
+}
+
+// Trash describes all deleted records, most recently deleted first.
+func (db *Database) Trash() []RecordInfo {
+	var list []RecordInfo
+	for _, name := range TableOrder {
+		tbl, _ := db.table(name)
+		list = append(list, tbl.trash()...)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].DeletedAt > list[j].DeletedAt
+	})
+	return list
+}
+
+// Restore clears the deleted flag of a record.
+func (db *Database) Restore(ref Ref) {
+	if tbl, err := db.table(ref.Table); err == nil {
+		tbl.restore(ref.ID)
+	}
+}
+
+// Parents returns the records a record refers to: its container in the
+// hierarchy and, for items, the category.
+func (db *Database) Parents(ref Ref) []Ref {
+	tbl, err := db.table(ref.Table)
+	if err != nil {
+		return nil
+	}
+	return tbl.parents(ref.ID)
+}
+
+// LiveChildren returns the live records that refer to a record.
+// Tag references are not included, see Tagged.
+func (db *Database) LiveChildren(ref Ref) []Ref {
+	var refs []Ref
+	for _, name := range TableOrder {
+		tbl, _ := db.table(name)
+		refs = append(refs, tbl.children(ref)...)
+	}
+	return refs
+}
+
+// Tagged returns all records carrying a tag, deleted ones included.
+func (db *Database) Tagged(tagid uint32) []Ref {
+	var refs []Ref
+	for _, name := range TableOrder {
+		tbl, _ := db.table(name)
+		refs = append(refs, tbl.tagged(tagid)...)
+	}
+	return refs
+}
+
+// Untag removes a tag from a record.
+func (db *Database) Untag(ref Ref, tagid uint32) {
+	if tbl, err := db.table(ref.Table); err == nil {
+		tbl.untag(ref.ID, tagid)
+	}
+}
+
+// Descendants returns all live records stored below a record, the direct
+// children first. Items only refer to their category, so a category has none.
+func (db *Database) Descendants(ref Ref) []Ref {
+	if ref.Table == "categories" {
+		return nil
+	}
+	refs := db.LiveChildren(ref)
+	for i := 0; i < len(refs); i++ {
+		refs = append(refs, db.LiveChildren(refs[i])...)
+	}
+	return refs
+}
+
+// Delete marks a record as deleted.
+func (db *Database) Delete(ref Ref) {
+	if tbl, err := db.table(ref.Table); err == nil {
+		tbl.softDelete(ref.ID)
+	}
+}
+
+// Reparent makes a record that refers to from refer to the record with id to
+// of the same table instead.
+func (db *Database) Reparent(ref Ref, from Ref, to uint32) {
+	if tbl, err := db.table(ref.Table); err == nil {
+		tbl.reparent(ref.ID, from, to)
+	}
+}
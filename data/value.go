@@ -0,0 +1,109 @@
+package data
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/elsni/lagerator/terminal"
+)
+
+// Money is an amount in cents of a currency.
+type Money int64
+
+// ParseMoney parses a price like 12.99 or 12,99. An empty string is no price.
+func ParseMoney(text string) (Money, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	units, cents, found := strings.Cut(strings.ReplaceAll(text, ",", "."), ".")
+	if found && (len(cents) == 0 || len(cents) > 2) {
+		return 0, fmt.Errorf("invalid price \"%s\", use at most two decimals", text)
+	}
+	for len(cents) < 2 {
+		cents += "0"
+	}
+	value, err := strconv.ParseUint(units+cents, 10, 63)
+	if err != nil || units == "" {
+		return 0, fmt.Errorf("invalid price \"%s\"", text)
+	}
+	return Money(value), nil
+}
+
+// String formats the amount with two decimals, "" for no price.
+func (m Money) String() string {
+	if m == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d.%02d", m/100, m%100)
+}
+
+// Totals sums money per currency.
+type Totals map[string]Money
+
+// Add adds an amount in a currency.
+func (t Totals) Add(amount Money, currency string) {
+	t[strings.ToUpper(currency)] += amount
+}
+
+// Merge adds all amounts of other.
+func (t Totals) Merge(other Totals) {
+	for currency, amount := range other {
+		t[currency] += amount
+	}
+}
+
+// String lists the sums sorted by currency, like "120.00 EUR + 35.50 USD".
+func (t Totals) String() string {
+	var currencies []string
+	for currency, amount := range t {
+		if amount != 0 {
+			currencies = append(currencies, currency)
+		}
+	}
+	if len(currencies) == 0 {
+		return "0.00"
+	}
+	slices.Sort(currencies)
+	parts := make([]string, len(currencies))
+	for i, currency := range currencies {
+		parts[i] = strings.TrimSpace(fmt.Sprintf("%d.%02d %s", t[currency]/100, t[currency]%100, currency))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// Value returns Amount times the unit price.
+func (d Item) Value() Money {
+	return Money(d.Amount) * d.UnitPrice
+}
+
+// ItemPath returns the box, shelf, room and warehouse an item is stored in.
+func ItemPath(d Item) []Ref {
+	shelfId := GetShelfIdforBox(d.BoxId)
+	roomId := GetRoomIdforShelf(shelfId)
+	return []Ref{{"boxes", d.BoxId}, {"shelves", shelfId}, {"rooms", roomId}, {"warehouses", GetWarehouseIdforRoom(roomId)}}
+}
+
+// ContainerValue sums the value of the live items in a container and
+// returns it with the number of items that have a price.
+func (db *Database) ContainerValue(container Ref) (Totals, int) {
+	totals := Totals{}
+	priced := 0
+	for _, set := range db.Items {
+		if set.Deleted || set.Data.UnitPrice == 0 || !slices.Contains(ItemPath(set.Data), container) {
+			continue
+		}
+		totals.Add(set.Data.Value(), set.Data.Currency)
+		priced++
+	}
+	return totals, priced
+}
+
+// showContainerValue prints the value of the items in a container.
+func showContainerValue(container Ref) {
+	if totals, priced := Db.ContainerValue(container); priced > 0 {
+		fmt.Printf("%s %s (%d items with price)\n", terminal.GetLabelText("Value"), totals, priced)
+	}
+}
@@ -0,0 +1,51 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Scope limits the item lists to a saved view when set, see UseView in logic.
+var Scope func(Dataset[Item]) bool
+
+// InScope reports whether an item belongs to the Scope, every item does if
+// no Scope is set.
+func InScope(set Dataset[Item]) bool {
+	return Scope == nil || Scope(set)
+}
+
+// scoped adds Scope to the filter of an item list.
+func scoped[T CustomData](filter func(Dataset[T]) bool) func(Dataset[T]) bool {
+	if Scope == nil {
+		return filter
+	}
+	return func(set Dataset[T]) bool {
+		if item, ok := any(set).(Dataset[Item]); ok && !InScope(item) {
+			return false
+		}
+		return filter == nil || filter(set)
+	}
+}
+
+// ViewNames returns the names of the saved views, sorted.
+func (db *Database) ViewNames() []string {
+	names := make([]string, 0, len(db.Views))
+	for name := range db.Views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ViewFilter returns the filter of a saved view.
+func (db *Database) ViewFilter(name string) (func(Dataset[Item]) bool, error) {
+	query, ok := db.Views[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown view \"%s\"", name)
+	}
+	filter, err := ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("view \"%s\": %w", name, err)
+	}
+	return filter, nil
+}
@@ -11,6 +11,11 @@ func (d Warehouse) GetTableRow(ownid uint32) string {
 	return ""
 }
 
+// GetColumns returns the warehouse fields.
+func (d Warehouse) GetColumns(ownid uint32) []Column {
+	return []Column{{"location", d.Location}}
+}
+
 // GetTableHeader returns the warehouse table header.
 func (d Warehouse) GetTableHeader() string {
 	return terminal.ResetColor()
@@ -20,6 +25,11 @@ func (d Warehouse) GetTableHeader() string {
 func (d Warehouse) Show() {
 }
 
+// ShowExtra prints the value of the items in the warehouse.
+func (d Warehouse) ShowExtra(ownid uint32) {
+	showContainerValue(Ref{"warehouses", ownid})
+}
+
 type WarehouseTable = DataTable[Warehouse]
 
 // Checks if a room exists in the given warehouse
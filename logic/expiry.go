@@ -0,0 +1,70 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/elsni/lagerator/data"
+	"github.com/elsni/lagerator/terminal"
+)
+
+// expiryDate and warrantyDate select the date the reports are about.
+var (
+	expiryDate   = func(d data.Item) data.Date { return d.Expires }
+	warrantyDate = func(d data.Item) data.Date { return d.WarrantyUntil }
+)
+
+// Expiring prints the items that expire within the given number of days, soonest first.
+func Expiring(days int) {
+	today := data.Today()
+	list := datedItems(expiryDate, today, today.AddDays(days))
+	printDated(list, "Expires", expiryDate, fmt.Sprintf("No items expire within %d days", days))
+}
+
+// Expired prints the items whose expiry date has passed, oldest first.
+func Expired() {
+	list := datedItems(expiryDate, 1, data.Today()-1)
+	printDated(list, "Expires", expiryDate, "No items have expired")
+}
+
+// Warranty prints the items whose warranty ends within the given number of days, soonest first.
+func Warranty(days int) {
+	today := data.Today()
+	list := datedItems(warrantyDate, today, today.AddDays(days))
+	printDated(list, "Warranty", warrantyDate, fmt.Sprintf("No warranty ends within %d days", days))
+}
+
+// datedItems returns the live items whose date is set and between from and to, sorted by date.
+func datedItems(date func(data.Item) data.Date, from, to data.Date) []data.Dataset[data.Item] {
+	var list []data.Dataset[data.Item]
+	for _, set := range data.Db.Items {
+		if d := date(set.Data); !set.Deleted && d != 0 && d >= from && d <= to && data.InScope(set) {
+			list = append(list, set)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return date(list[i].Data) < date(list[j].Data) })
+	return list
+}
+
+// printDated prints items with a date column, past dates highlighted.
+func printDated(list []data.Dataset[data.Item], title string, date func(data.Item) data.Date, empty string) {
+	if data.Output != data.OutputText {
+		data.WriteSets(list)
+		return
+	}
+	if len(list) == 0 {
+		fmt.Println(empty)
+		return
+	}
+	fmt.Println(terminal.GetHeadlineText(fmt.Sprintf("%5s %-30s %-10s %6s %6s %-15s", "ID", "Name", title, "Days", "Amount", "Box")))
+	for _, set := range list {
+		d := date(set.Data)
+		line := fmt.Sprintf("%5d %-30s %-10s %6d %6d %-15s", set.ID, set.GetPrintName(30), d, d.DaysUntil(),
+			set.Data.Amount, data.GetPrintNameById(&data.Db.Boxes, set.Data.BoxId, 15))
+		if d < data.Today() {
+			line = terminal.GetWarningText(line)
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("%d items\n", len(list))
+}
@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elsni/lagerator/data"
+)
+
+// TestExpiry verifies the expiring and expired reports.
+func TestExpiry(t *testing.T) {
+	resetDb()
+	today := data.Today()
+	data.Db.Items.Add(data.NewDataset("Plaster", data.Item{Expires: today.AddDays(-3)}))
+	data.Db.Items.Add(data.NewDataset("Beans", data.Item{Expires: today.AddDays(20)}))
+	data.Db.Items.Add(data.NewDataset("Milk", data.Item{Expires: today}))
+	data.Db.Items.Add(data.NewDataset("Rice", data.Item{Expires: today.AddDays(90)}))
+	data.Db.Items.Add(data.NewDataset("Drill", data.Item{}))
+
+	out := captureOutput(t, func() { Expiring(30) })
+	if !strings.Contains(out, "2 items") || strings.Index(out, "Milk") > strings.Index(out, "Beans") || strings.Contains(out, "Rice") {
+		t.Fatalf("unexpected expiring report: %s", out)
+	}
+	out = captureOutput(t, Expired)
+	if !strings.Contains(out, "Plaster") || !strings.Contains(out, "1 items") {
+		t.Fatalf("unexpected expired report: %s", out)
+	}
+	out = captureOutput(t, func() { Expiring(100) })
+	if !strings.Contains(out, "Rice") || strings.Contains(out, "Plaster") {
+		t.Fatalf("unexpected expiring report: %s", out)
+	}
+}
+
+// TestWarranty verifies the warranty report.
+func TestWarranty(t *testing.T) {
+	resetDb()
+	today := data.Today()
+	data.Db.Items.Add(data.NewDataset("Fridge", data.Item{WarrantyUntil: today.AddDays(-1)}))
+	data.Db.Items.Add(data.NewDataset("Drill", data.Item{WarrantyUntil: today.AddDays(50), Receipt: "folder 3"}))
+	data.Db.Items.Add(data.NewDataset("Washer", data.Item{WarrantyUntil: today.AddDays(400)}))
+
+	out := captureOutput(t, func() { Warranty(60) })
+	if !strings.Contains(out, "Drill") || strings.Contains(out, "Fridge") || strings.Contains(out, "Washer") {
+		t.Fatalf("unexpected warranty report: %s", out)
+	}
+	if data.Db.Items[0].Data.UnderWarranty() || !data.Db.Items[2].Data.UnderWarranty() {
+		t.Fatal("unexpected warranty state")
+	}
+	out = captureOutput(t, func() { data.Db.Items[1].Show() })
+	if !strings.Contains(out, "folder 3") {
+		t.Fatalf("expected receipt in details, got: %s", out)
+	}
+}
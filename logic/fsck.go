@@ -0,0 +1,112 @@
+package logic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elsni/lagerator/data"
+	"github.com/elsni/lagerator/terminal"
+)
+
+// lostAndFoundName is the name of the containers orphans are moved to.
+const lostAndFoundName = "Lost & Found"
+
+// FsckOptions selects the problems Fsck repairs.
+type FsckOptions struct {
+	// Orphans moves orphans to Lost & Found and clears dangling categories
+	Orphans bool
+	// Tags strips dangling tag ids
+	Tags bool
+}
+
+// Fsck reports integrity problems of the database and repairs the ones selected by opts.
+func Fsck(opts FsckOptions) {
+	problems := data.Db.Check()
+	if len(problems) == 0 {
+		fmt.Println("No problems found")
+		return
+	}
+	fmt.Println(terminal.GetHeadlineText(fmt.Sprintf("%-18s %-10s %5s %-30s %-30s", "Problem", "Type", "ID", "Name", "Detail")))
+	for _, p := range problems {
+		fmt.Printf("%-18s %-10s %5d %-30s %s\n", p.Kind, p.Type, p.ID, p.Name, p.Detail)
+	}
+	fmt.Printf("%d problems found\n", len(problems))
+	if !opts.Orphans && !opts.Tags {
+		fmt.Println("Run \"lgrt fsck --fix\" to move orphans to \"" + lostAndFoundName + "\" and strip dangling tags")
+		return
+	}
+
+	t := begin("fsck")
+	moved, cleared, stripped := 0, 0, 0
+	for _, p := range problems {
+		switch {
+		case p.Kind == data.ProblemOrphan && opts.Orphans:
+			t.touchRef(p.Ref)
+			data.Db.Reparent(p.Ref, p.Parent, lostAndFound(t, p.Parent.Table))
+			moved++
+		case p.Kind == data.ProblemDanglingRef && opts.Orphans:
+			t.touchRef(p.Ref)
+			data.Db.Reparent(p.Ref, p.Parent, 0)
+			cleared++
+		case p.Kind == data.ProblemDanglingTag && opts.Tags:
+			t.touchRef(p.Ref)
+			data.Db.Untag(p.Ref, p.TagId)
+			stripped++
+		}
+	}
+	if moved+cleared+stripped == 0 {
+		fmt.Println("Nothing to fix")
+		return
+	}
+	var done []string
+	if moved > 0 {
+		done = append(done, fmt.Sprintf("moved %d orphans to \"%s\"", moved, lostAndFoundName))
+	}
+	if cleared > 0 {
+		done = append(done, fmt.Sprintf("cleared %d dangling categories", cleared))
+	}
+	if stripped > 0 {
+		done = append(done, fmt.Sprintf("stripped %d dangling tags", stripped))
+	}
+	summary := "fsck: " + strings.Join(done, ", ")
+	if !t.commit(summary) {
+		return
+	}
+	fmt.Println(summary)
+}
+
+// lostAndFound returns the id of the Lost & Found container in a table,
+// creating it and its own containers if necessary.
+func lostAndFound(t *tx, table string) uint32 {
+	switch table {
+	case "warehouses":
+		if id, found := data.Db.Warehouses.GetFirstOccurance(lostAndFoundName); found {
+			return id
+		}
+		return addLostAndFound(t, &data.Db.Warehouses, data.Warehouse{})
+	case "rooms":
+		if id, found := data.Db.Rooms.GetFirstOccurance(lostAndFoundName); found {
+			return id
+		}
+		return addLostAndFound(t, &data.Db.Rooms, data.Room{WarehouseId: lostAndFound(t, "warehouses")})
+	case "shelves":
+		if id, found := data.Db.Shelves.GetFirstOccurance(lostAndFoundName); found {
+			return id
+		}
+		return addLostAndFound(t, &data.Db.Shelves, data.Shelf{RoomId: lostAndFound(t, "rooms")})
+	case "boxes":
+		if id, found := data.Db.Boxes.GetFirstOccurance(lostAndFoundName); found {
+			return id
+		}
+		return addLostAndFound(t, &data.Db.Boxes, data.Box{ShelfId: lostAndFound(t, "shelves")})
+	}
+	return 0
+}
+
+// addLostAndFound adds a Lost & Found container to a table.
+func addLostAndFound[T data.CustomData](t *tx, tbl *data.DataTable[T], d T) uint32 {
+	set := data.NewDataset(lostAndFoundName, d)
+	touch(t, tbl, set.ID)
+	tbl.Add(set)
+	return set.ID
+}
@@ -0,0 +1,377 @@
+package logic
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/elsni/lagerator/data"
+)
+
+// CSVImportOptions controls ImportCSV.
+type CSVImportOptions struct {
+	// DryRun prints the planned changes without saving them
+	DryRun bool
+	// Create adds missing warehouses, rooms, shelves and boxes given by path
+	Create bool
+	// Mapping maps item fields to column headers, overriding the detected columns
+	Mapping map[string]string
+}
+
+// csvFields lists the item fields that can be imported and the column headers they are detected by.
+var csvFields = []struct {
+	name    string
+	headers []string
+}{
+	{"name", []string{"name", "item"}},
+	{"description", []string{"description", "desc"}},
+	{"location", []string{"location"}},
+	{"condition", []string{"condition"}},
+	{"amount", []string{"amount", "amnt", "quantity", "qty"}},
+	{"box", []string{"box", "boxid"}},
+	{"category", []string{"category", "categoryid"}},
+	{"tags", []string{"tags", "tag"}},
+	{"expires", []string{"expires", "expiry", "best before"}},
+	{"price", []string{"price", "unit price", "unitprice"}},
+	{"currency", []string{"currency"}},
+	{"purchased", []string{"purchased", "purchase date"}},
+	{"vendor", []string{"vendor", "shop"}},
+	{"serial", []string{"serial", "serial number"}},
+	{"warranty", []string{"warranty", "warranty until", "warrantyuntil"}},
+	{"receipt", []string{"receipt"}},
+}
+
+// csvImport holds the state of one import run.
+type csvImport struct {
+	t       *tx
+	opts    CSVImportOptions
+	created []string
+}
+
+// ImportCSV adds the items of a CSV file. The first line holds the column
+// headers, the delimiter (comma, semicolon or tab) is detected from it.
+// Missing categories and tags are created, missing boxes only if the box is
+// given as path "[warehouse/]room/shelf/box" and opts.Create is set.
+func ImportCSV(path string, opts CSVImportOptions) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	r := csv.NewReader(bytes.NewReader(content))
+	r.Comma = detectDelimiter(content)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err != nil {
+		fmt.Printf("Error: cannot read header of %s: %v\n", path, err)
+		return
+	}
+	columns, err := mapColumns(header, opts.Mapping)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	imp := &csvImport{t: begin("import"), opts: opts}
+	imported, skipped := 0, 0
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			fmt.Printf("Line %d: %v\n", parseErr.Line, parseErr.Err)
+			skipped++
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Error: cannot read %s: %v\n", path, err)
+			return
+		}
+		line, _ := r.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		item, err := imp.item(field)
+		if err != nil {
+			fmt.Printf("Line %d: %v\n", line, err)
+			skipped++
+			continue
+		}
+		imp.t.touchRef(data.Ref{Table: "items", ID: item.ID})
+		data.Db.Items.Add(item)
+		if opts.DryRun {
+			fmt.Printf("+ item \"%s\" (%d) in box \"%s\"\n", item.Name, item.Data.Amount, data.GetPrintNameById(&data.Db.Boxes, item.Data.BoxId, 999))
+		}
+		imported++
+	}
+
+	if opts.DryRun {
+		for _, c := range imp.created {
+			fmt.Println("+ " + c)
+		}
+		fmt.Printf("Dry run: %d items would be imported, %d rows skipped, nothing was saved\n", imported, skipped)
+		return
+	}
+	if imported == 0 && len(imp.created) == 0 {
+		fmt.Printf("Nothing imported, %d rows skipped\n", skipped)
+		return
+	}
+	if !imp.t.commit(fmt.Sprintf("import %d items from %s", imported, filepath.Base(path))) {
+		return
+	}
+	for _, c := range imp.created {
+		fmt.Println("Created " + c)
+	}
+	fmt.Printf("Imported %d items, %d rows skipped\n", imported, skipped)
+}
+
+// detectDelimiter picks the most frequent of comma, semicolon and tab in the first line.
+func detectDelimiter(content []byte) rune {
+	first, _, _ := bytes.Cut(content, []byte("\n"))
+	best, count := ',', bytes.Count(first, []byte(","))
+	for _, d := range []rune{';', '\t'} {
+		if n := bytes.Count(first, []byte(string(d))); n > count {
+			best, count = d, n
+		}
+	}
+	return best
+}
+
+// mapColumns returns the column index of every item field found in the header.
+func mapColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	index := map[string]int{}
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(h))
+		if _, ok := index[key]; !ok {
+			index[key] = i
+		}
+	}
+	columns := map[string]int{}
+	for _, f := range csvFields {
+		for _, h := range f.headers {
+			if i, ok := index[h]; ok {
+				columns[f.name] = i
+				break
+			}
+		}
+	}
+	for field, column := range mapping {
+		known := false
+		for _, f := range csvFields {
+			known = known || f.name == field
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown item field \"%s\"", field)
+		}
+		i, ok := index[strings.ToLower(column)]
+		if !ok {
+			return nil, fmt.Errorf("no column \"%s\" for %s", column, field)
+		}
+		columns[field] = i
+	}
+	for _, required := range []string{"name", "box"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("no column for %s, map one with --map %s=<column>", required, required)
+		}
+	}
+	return columns, nil
+}
+
+// item builds an item from the fields of a row, creating its category and tags.
+func (imp *csvImport) item(field func(string) string) (data.Dataset[data.Item], error) {
+	var item data.Dataset[data.Item]
+	name := field("name")
+	if name == "" {
+		return item, fmt.Errorf("name is empty")
+	}
+	amount := 1
+	if a := field("amount"); a != "" {
+		n, err := strconv.Atoi(a)
+		if err != nil || n < 0 {
+			return item, fmt.Errorf("invalid amount \"%s\"", a)
+		}
+		amount = n
+	}
+	expires, err := data.ParseDate(field("expires"))
+	if err != nil {
+		return item, err
+	}
+	purchased, err := data.ParseDate(field("purchased"))
+	if err != nil {
+		return item, err
+	}
+	price, err := data.ParseMoney(field("price"))
+	if err != nil {
+		return item, err
+	}
+	warranty, err := data.ParseDate(field("warranty"))
+	if err != nil {
+		return item, err
+	}
+	boxid, err := imp.box(field("box"))
+	if err != nil {
+		return item, err
+	}
+	item = data.NewDataset(name, data.Item{
+		Location:      field("location"),
+		Condition:     field("condition"),
+		Amount:        amount,
+		BoxId:         boxid,
+		CategoryId:    imp.category(field("category")),
+		Expires:       expires,
+		UnitPrice:     price,
+		Currency:      field("currency"),
+		Purchased:     purchased,
+		Vendor:        field("vendor"),
+		Serial:        field("serial"),
+		WarrantyUntil: warranty,
+		Receipt:       field("receipt"),
+	})
+	item.Description = field("description")
+	item.Tags = imp.tags(field("tags"))
+	return item, nil
+}
+
+// category resolves a category name or id, creating a missing category.
+func (imp *csvImport) category(name string) uint32 {
+	if name == "" {
+		return 0
+	}
+	if catid, found := data.Db.Categories.GetFirstOccurance(name); found {
+		return catid
+	}
+	if catid, err := strconv.ParseUint(name, 10, 32); err == nil {
+		if _, ok := data.Db.Categories.GetPtr(uint32(catid)); ok {
+			return uint32(catid)
+		}
+	}
+	return addImported(imp, &data.Db.Categories, name, data.Category{}, "category")
+}
+
+// tags resolves a comma-separated list of tag names, creating missing tags.
+func (imp *csvImport) tags(list string) []uint32 {
+	var missing []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if _, found := data.Db.Tags.GetFirstOccurance(name); name != "" && !found && !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	ids := data.GetTagIds(list)
+	for _, name := range missing {
+		tagid, _ := data.Db.Tags.GetFirstOccurance(name)
+		imp.t.created(data.Ref{Table: "tags", ID: tagid})
+		imp.created = append(imp.created, fmt.Sprintf("tag \"%s\"", name))
+	}
+	return ids
+}
+
+// box resolves a box id, name or "[warehouse/]room/shelf/box" path.
+func (imp *csvImport) box(value string) (uint32, error) {
+	if value == "" {
+		return 0, fmt.Errorf("box is empty")
+	}
+	if strings.Contains(value, "/") {
+		return imp.boxPath(strings.Split(value, "/"))
+	}
+	sets := data.Db.Boxes.GetSetsByName(value)
+	switch {
+	case len(sets) == 1:
+		return sets[0].ID, nil
+	case len(sets) > 1:
+		return 0, fmt.Errorf("box name \"%s\" is ambiguous, use the id or a path", value)
+	}
+	if boxid, err := strconv.ParseUint(value, 10, 32); err == nil {
+		if _, ok := data.Db.Boxes.GetPtr(uint32(boxid)); ok {
+			return uint32(boxid), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown box \"%s\"", value)
+}
+
+// boxPath resolves a box path, creating missing parts if enabled.
+func (imp *csvImport) boxPath(parts []string) (uint32, error) {
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+		if parts[i] == "" {
+			return 0, fmt.Errorf("empty name in box path \"%s\"", strings.Join(parts, "/"))
+		}
+	}
+	path := strings.Join(parts, "/")
+	var whid uint32
+	switch len(parts) {
+	case 3:
+		if !CurrentWarehouseExists() {
+			return 0, fmt.Errorf("box path \"%s\" needs a current warehouse, switch to one or start the path with the warehouse", path)
+		}
+		whid = data.Db.CurrentWarehouse
+	case 4:
+		id, found := data.Db.Warehouses.GetFirstOccurance(parts[0])
+		if !found && !imp.opts.Create {
+			return 0, fmt.Errorf("unknown warehouse \"%s\", use --create to add it", parts[0])
+		}
+		if !found {
+			id = addImported(imp, &data.Db.Warehouses, parts[0], data.Warehouse{}, "warehouse")
+		}
+		whid = id
+		parts = parts[1:]
+	default:
+		return 0, fmt.Errorf("box path \"%s\" must be room/shelf/box or warehouse/room/shelf/box", path)
+	}
+
+	roomid, found := findListentry(data.GetRoomNamesforWarehouse(&data.Db.Rooms, whid), parts[0])
+	if !found && !imp.opts.Create {
+		return 0, fmt.Errorf("unknown room \"%s\", use --create to add it", parts[0])
+	}
+	if !found {
+		roomid = addImported(imp, &data.Db.Rooms, parts[0], data.Room{WarehouseId: whid}, "room")
+	}
+	shelfid, found := findListentry(data.GetShelfNamesforRoom(&data.Db.Shelves, roomid), parts[1])
+	if !found && !imp.opts.Create {
+		return 0, fmt.Errorf("unknown shelf \"%s\", use --create to add it", parts[1])
+	}
+	if !found {
+		shelfid = addImported(imp, &data.Db.Shelves, parts[1], data.Shelf{RoomId: roomid}, "shelf")
+	}
+	boxid, found := findListentry(data.GetBoxNamesforShelf(&data.Db.Boxes, shelfid), parts[2])
+	if !found && !imp.opts.Create {
+		return 0, fmt.Errorf("unknown box \"%s\", use --create to add it", parts[2])
+	}
+	if !found {
+		boxid = addImported(imp, &data.Db.Boxes, parts[2], data.Box{ShelfId: shelfid}, "box")
+	}
+	return boxid, nil
+}
+
+// addImported adds a new dataset during the import and remembers it for the report.
+func addImported[T data.CustomData](imp *csvImport, tbl *data.DataTable[T], name string, d T, kind string) uint32 {
+	set := data.NewDataset(name, d)
+	touch(imp.t, tbl, set.ID)
+	tbl.Add(set)
+	imp.created = append(imp.created, fmt.Sprintf("%s \"%s\"", kind, name))
+	return set.ID
+}
+
+// findListentry returns the id of the entry with the given name, ignoring case.
+func findListentry(list []data.Listentry, name string) (uint32, bool) {
+	for _, e := range list {
+		if strings.EqualFold(e.Name, name) {
+			return e.Id, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,98 @@
+package logic
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/elsni/lagerator/data"
+)
+
+// writeCSV writes content to a temporary CSV file and returns its path.
+func writeCSV(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "items.csv")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	return path
+}
+
+// TestImportCSV verifies column detection, path creation and category and tag creation.
+func TestImportCSV(t *testing.T) {
+	resetDb()
+	AddWarehouse("Home")
+	SwitchWarehouse("Home")
+	path := writeCSV(t, "Artikel;Qty;Box;Category;Tags\n"+
+		"Drill;2;Basement/Rack/Tools;Power tools;blue, heavy\n"+
+		"Saw;x;Basement/Rack/Tools;;\n"+
+		"Hammer;1;Nowhere;;\n"+
+		"Screws;100;Basement/Rack/Tools;Power tools;blue\n")
+
+	out := captureOutput(t, func() {
+		ImportCSV(path, CSVImportOptions{Create: true, Mapping: map[string]string{"name": "Artikel"}})
+	})
+	if !strings.Contains(out, "Line 3: invalid amount") || !strings.Contains(out, "Line 4: unknown box") {
+		t.Fatalf("expected row errors, got: %s", out)
+	}
+	if !strings.Contains(out, "Imported 2 items, 2 rows skipped") {
+		t.Fatalf("expected summary, got: %s", out)
+	}
+	if len(data.Db.Items) != 2 || len(data.Db.Rooms) != 1 || len(data.Db.Boxes) != 1 || len(data.Db.Categories) != 1 || len(data.Db.Tags) != 2 {
+		t.Fatalf("unexpected database: %d items, %d rooms, %d boxes, %d categories, %d tags",
+			len(data.Db.Items), len(data.Db.Rooms), len(data.Db.Boxes), len(data.Db.Categories), len(data.Db.Tags))
+	}
+	if data.Db.Items[0].Data.Amount != 2 || data.GetTagList(data.Db.Items[0].Tags) != "blue, heavy" {
+		t.Fatalf("unexpected item: %+v", data.Db.Items[0])
+	}
+
+	captureOutput(t, Undo)
+	if len(data.Db.Items) != 0 || len(data.Db.Tags) != 0 || len(data.Db.Rooms) != 0 {
+		t.Fatal("expected undo to remove the imported records")
+	}
+}
+
+// TestImportCSVMalformedRow verifies that a row the CSV reader rejects is
+// skipped with its line number.
+func TestImportCSVMalformedRow(t *testing.T) {
+	resetDb()
+	AddWarehouse("Home")
+	SwitchWarehouse("Home")
+	AddRoomToCurrentWarehouse("Basement")
+	AddShelfToRoom("Rack", "Basement")
+	AddBoxToShelf("Tools", "Rack")
+	path := writeCSV(t, "name;amount;box\nDrill;1;Tools\nSa\"w;2;Tools\nHammer;3;Tools\n")
+
+	out := captureOutput(t, func() { ImportCSV(path, CSVImportOptions{}) })
+	if !strings.Contains(out, "Line 3: bare \" in non-quoted-field") {
+		t.Fatalf("expected parse error with line, got: %s", out)
+	}
+	if !strings.Contains(out, "Imported 2 items, 1 rows skipped") {
+		t.Fatalf("expected summary, got: %s", out)
+	}
+}
+
+// TestImportCSVDryRun verifies that a dry run reports the plan without saving it.
+func TestImportCSVDryRun(t *testing.T) {
+	resetDb()
+	AddWarehouse("Home")
+	SwitchWarehouse("Home")
+	path := writeCSV(t, "name,box\nDrill,Basement/Rack/Tools\n")
+
+	out := captureOutput(t, func() { ImportCSV(path, CSVImportOptions{}) })
+	if !strings.Contains(out, "unknown room \"Basement\", use --create") {
+		t.Fatalf("expected missing room error, got: %s", out)
+	}
+	out = captureOutput(t, func() { ImportCSV(path, CSVImportOptions{DryRun: true, Create: true}) })
+	if !strings.Contains(out, "+ item \"Drill\"") || !strings.Contains(out, "+ box \"Tools\"") || !strings.Contains(out, "nothing was saved") {
+		t.Fatalf("expected plan, got: %s", out)
+	}
+	data.Db = data.NewDatabase()
+	if err := data.Db.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(data.Db.Items) != 0 || len(data.Db.Rooms) != 0 {
+		t.Fatal("expected dry run not to save anything")
+	}
+}
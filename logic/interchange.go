@@ -0,0 +1,212 @@
+package logic
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/elsni/lagerator/data"
+	"github.com/elsni/lagerator/id"
+	"github.com/elsni/lagerator/terminal"
+)
+
+// Strategies for imported records whose name is already used in the same place.
+const (
+	// ConflictMerge uses the existing record, the contents of both are merged
+	ConflictMerge = "merge"
+	// ConflictRename imports the record under a new name
+	ConflictRename = "rename"
+	// ConflictSkip leaves out the record and everything it contains
+	ConflictSkip = "skip"
+)
+
+// Export writes the inventory to an interchange file. Without a warehouse
+// name all warehouses are exported, with a room name only that room.
+func Export(path, warehouse, room string) {
+	var warehouseIds []uint32
+	var roomId uint32
+	switch {
+	case room != "" && warehouse != "":
+		idx := SelectSet(&data.Db.Warehouses, warehouse, "Warehouse", "export")
+		if idx < 0 {
+			return
+		}
+		whid := data.Db.Warehouses[idx].ID
+		var ok bool
+		if roomId, ok = findListentry(data.GetRoomNamesforWarehouse(&data.Db.Rooms, whid), room); !ok {
+			fmt.Printf("No room with name \"%s\" found in warehouse \"%s\".\n", room, warehouse)
+			return
+		}
+		warehouseIds = []uint32{whid}
+	case room != "":
+		idx := SelectSet(&data.Db.Rooms, room, "Room", "export")
+		if idx < 0 {
+			return
+		}
+		roomId = data.Db.Rooms[idx].ID
+		warehouseIds = []uint32{data.Db.Rooms[idx].Data.WarehouseId}
+	case warehouse != "":
+		idx := SelectSet(&data.Db.Warehouses, warehouse, "Warehouse", "export")
+		if idx < 0 {
+			return
+		}
+		warehouseIds = []uint32{data.Db.Warehouses[idx].ID}
+	default:
+		for _, set := range data.Db.Warehouses {
+			warehouseIds = append(warehouseIds, set.ID)
+		}
+	}
+	e := data.Db.Export(warehouseIds, roomId)
+	if err := e.Write(path); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("Exported %d warehouses, %d rooms, %d shelves, %d boxes, %d items, %d categories and %d tags to %s\n",
+		len(e.Warehouses), len(e.Rooms), len(e.Shelves), len(e.Boxes), len(e.Items), len(e.Categories), len(e.Tags), path)
+}
+
+// importCount counts what happened to the records of one table.
+type importCount struct {
+	added, renamed, merged, skipped int
+}
+
+// exchangeImport holds the state of one import of an interchange file.
+type exchangeImport struct {
+	t        *tx
+	strategy string
+	// ids maps records of the file to their ids in the database
+	ids    map[data.Ref]uint32
+	counts map[string]*importCount
+}
+
+// Import merges an interchange file into the database. All records get new
+// ids. Records whose name is already used in the same place are handled by
+// strategy, categories and tags with a known name are always merged.
+func Import(path, strategy string, dryRun bool) {
+	if !slices.Contains([]string{ConflictMerge, ConflictRename, ConflictSkip}, strategy) {
+		fmt.Printf("Error: unknown conflict strategy \"%s\", use merge, rename or skip\n", strategy)
+		return
+	}
+	e, err := data.ReadExport(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	imp := &exchangeImport{t: begin("import"), strategy: strategy, ids: map[data.Ref]uint32{}, counts: map[string]*importCount{}}
+	importSets(imp, e.Tags, &data.Db.Tags)
+	importSets(imp, e.Categories, &data.Db.Categories)
+	importSets(imp, e.Warehouses, &data.Db.Warehouses)
+	importSets(imp, e.Rooms, &data.Db.Rooms)
+	importSets(imp, e.Shelves, &data.Db.Shelves)
+	importSets(imp, e.Boxes, &data.Db.Boxes)
+	importSets(imp, e.Items, &data.Db.Items)
+
+	added := 0
+	for _, c := range imp.counts {
+		added += c.added + c.renamed
+	}
+	if dryRun {
+		imp.report()
+		fmt.Printf("Dry run: %d records would be added, nothing was saved\n", added)
+		return
+	}
+	if added == 0 {
+		imp.report()
+		fmt.Println("Nothing imported")
+		return
+	}
+	if !imp.t.commit(fmt.Sprintf("import %d records from %s", added, filepath.Base(path))) {
+		return
+	}
+	imp.report()
+}
+
+// importSets copies the live sets of one table of the file into the database.
+func importSets[T data.CustomData](imp *exchangeImport, src data.DataTable[T], dst *data.DataTable[T]) {
+	table := data.Db.TableName(dst)
+	count := &importCount{}
+	imp.counts[table] = count
+	for _, set := range src {
+		if set.Deleted {
+			continue
+		}
+		var parent data.Ref
+		if container, ok := set.Container(); ok {
+			parentId, found := imp.ids[container]
+			if !found {
+				// the container was skipped or is not part of the file
+				count.skipped++
+				continue
+			}
+			parent = data.Ref{Table: container.Table, ID: parentId}
+		}
+		name := set.Name
+		if existing, found := findSibling(dst, name, parent); found {
+			strategy := imp.strategy
+			if table == "categories" || table == "tags" {
+				strategy = ConflictMerge
+			}
+			switch strategy {
+			case ConflictMerge:
+				imp.ids[data.Ref{Table: table, ID: set.ID}] = existing
+				count.merged++
+				continue
+			case ConflictSkip:
+				count.skipped++
+				continue
+			}
+			name = uniqueName(dst, name, parent)
+			count.renamed++
+		} else {
+			count.added++
+		}
+
+		copied := set
+		copied.ID = id.IdSource.GetNewId()
+		copied.Name = name
+		copied.Tags = make([]uint32, 0, len(set.Tags))
+		for _, tagid := range set.Tags {
+			if newid, ok := imp.ids[data.Ref{Table: "tags", ID: tagid}]; ok {
+				copied.Tags = append(copied.Tags, newid)
+			}
+		}
+		touch(imp.t, dst, copied.ID)
+		dst.Add(copied)
+		ref := data.Ref{Table: table, ID: copied.ID}
+		for _, r := range set.References() {
+			data.Db.Reparent(ref, r, imp.ids[r])
+		}
+		imp.ids[data.Ref{Table: table, ID: set.ID}] = copied.ID
+	}
+}
+
+// findSibling returns the id of a live set with the given name in the
+// container parent. Sets without container are compared by name only.
+func findSibling[T data.CustomData](tbl *data.DataTable[T], name string, parent data.Ref) (uint32, bool) {
+	table := data.Db.TableName(tbl)
+	for _, set := range tbl.GetSetsByName(name) {
+		if parent.Table == "" || slices.Contains(data.Db.Parents(data.Ref{Table: table, ID: set.ID}), parent) {
+			return set.ID, true
+		}
+	}
+	return 0, false
+}
+
+// uniqueName appends a number to name until it is not used in parent.
+func uniqueName[T data.CustomData](tbl *data.DataTable[T], name string, parent data.Ref) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", name, n)
+		if _, found := findSibling(tbl, candidate, parent); !found {
+			return candidate
+		}
+	}
+}
+
+// report prints what happened to the records of every table.
+func (imp *exchangeImport) report() {
+	fmt.Println(terminal.GetHeadlineText(fmt.Sprintf("%-12s %7s %7s %7s %7s", "Table", "Added", "Renamed", "Merged", "Skipped")))
+	for _, table := range data.TableOrder {
+		c := imp.counts[table]
+		fmt.Printf("%-12s %7d %7d %7d %7d\n", table, c.added, c.renamed, c.merged, c.skipped)
+	}
+}
@@ -0,0 +1,76 @@
+package logic
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/elsni/lagerator/data"
+)
+
+// exportFixture fills the database with two rooms and exports one of them.
+func exportFixture(t *testing.T) string {
+	t.Helper()
+	resetDb()
+	AddWarehouse("Home")
+	SwitchWarehouse("Home")
+	csv := writeCSV(t, "name,box,category,tags\n"+
+		"Drill,Basement/Rack/Tools,Power tools,blue\n"+
+		"Lamp,Attic/Beam/Misc,,\n")
+	captureOutput(t, func() { ImportCSV(csv, CSVImportOptions{Create: true}) })
+	path := filepath.Join(t.TempDir(), "export.json")
+	out := captureOutput(t, func() { Export(path, "", "Basement") })
+	if !strings.Contains(out, "Exported 1 warehouses, 1 rooms, 1 shelves, 1 boxes, 1 items, 1 categories and 1 tags") {
+		t.Fatalf("unexpected export: %s", out)
+	}
+	return path
+}
+
+// TestImportMerge verifies that existing containers with the same name are reused.
+func TestImportMerge(t *testing.T) {
+	path := exportFixture(t)
+	resetDb()
+	AddWarehouse("Home")
+	SwitchWarehouse("Home")
+	AddRoomToCurrentWarehouse("Basement")
+	data.Db.Categories.AddSimple("power tools")
+
+	out := captureOutput(t, func() { Import(path, ConflictMerge, false) })
+	if len(data.Db.Warehouses) != 1 || len(data.Db.Rooms) != 1 || len(data.Db.Shelves) != 1 || len(data.Db.Items) != 1 || len(data.Db.Categories) != 1 {
+		t.Fatalf("expected merged hierarchy, got: %s", out)
+	}
+	item := data.Db.Items[0]
+	box, _ := data.Db.Boxes.GetPtr(item.Data.BoxId)
+	shelf, _ := data.Db.Shelves.GetPtr(box.Data.ShelfId)
+	if shelf.Data.RoomId != data.Db.Rooms[0].ID || item.Data.CategoryId != data.Db.Categories[0].ID {
+		t.Fatalf("references not remapped: %+v", item)
+	}
+	if data.GetTagList(item.Tags) != "blue" {
+		t.Fatalf("expected tag blue, got %q", data.GetTagList(item.Tags))
+	}
+
+	captureOutput(t, func() { Import(path, ConflictMerge, false) })
+	if len(data.Db.Items) != 1 {
+		t.Fatalf("expected a second import to merge the item, got %d items", len(data.Db.Items))
+	}
+}
+
+// TestImportRenameAndSkip verifies the rename and skip conflict strategies.
+func TestImportRenameAndSkip(t *testing.T) {
+	path := exportFixture(t)
+	out := captureOutput(t, func() { Import(path, ConflictSkip, false) })
+	if !strings.Contains(out, "Nothing imported") || len(data.Db.Rooms) != 2 {
+		t.Fatalf("expected skip to import nothing, got: %s", out)
+	}
+
+	captureOutput(t, func() { Import(path, ConflictRename, false) })
+	if _, found := data.Db.Warehouses.GetFirstOccurance("Home (2)"); !found {
+		t.Fatal("expected renamed warehouse")
+	}
+	if len(data.Db.Rooms) != 3 || len(data.Db.Items) != 3 || len(data.Db.Tags) != 1 {
+		t.Fatalf("expected a renamed copy, got %d rooms, %d items, %d tags", len(data.Db.Rooms), len(data.Db.Items), len(data.Db.Tags))
+	}
+	if problems := data.Db.Check(); len(problems) != 0 {
+		t.Fatalf("import left problems: %+v", problems)
+	}
+}
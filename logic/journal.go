@@ -0,0 +1,215 @@
+package logic
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/elsni/lagerator/data"
+	"github.com/elsni/lagerator/terminal"
+)
+
+// tx collects the records a mutating command changes, so the change can be
+// written to the journal and undone later.
+type tx struct {
+	op      string
+	changes []data.Change
+	seen    map[string]bool
+	// records is set if only the touched records change, see beginRecords
+	records bool
+}
+
+// begin starts recording a mutating command.
+func begin(op string) *tx {
+	return &tx{op: op, seen: map[string]bool{}}
+}
+
+// beginRecords is begin for a command that changes nothing but the records
+// it touches. Only these are saved, instead of the whole database.
+func beginRecords(op string) *tx {
+	t := begin(op)
+	t.records = true
+	return t
+}
+
+// touch remembers the state of a record before it is changed or added.
+func touch[T data.CustomData](t *tx, tbl *data.DataTable[T], id uint32) {
+	t.touchRef(data.Ref{Table: data.Db.TableName(tbl), ID: id})
+}
+
+// touchRef is touch for a record given by table name and id.
+func (t *tx) touchRef(ref data.Ref) {
+	table, id := ref.Table, ref.ID
+	key := fmt.Sprintf("%s/%d", table, id)
+	if t.seen[key] {
+		return
+	}
+	t.seen[key] = true
+	before, _ := data.Db.GetRecord(table, id)
+	t.changes = append(t.changes, data.Change{Table: table, ID: id, Before: before})
+}
+
+// touchValue is touch for a database value that is not a table, given by
+// its JSON name.
+func (t *tx) touchValue(name string) {
+	t.touchRef(data.Ref{Table: name})
+}
+
+// created records a record that was added before its id was known, like a
+// tag created by data.GetTagIds.
+func (t *tx) created(ref data.Ref) {
+	key := fmt.Sprintf("%s/%d", ref.Table, ref.ID)
+	if t.seen[key] {
+		return
+	}
+	t.seen[key] = true
+	t.changes = append(t.changes, data.Change{Table: ref.Table, ID: ref.ID})
+}
+
+// reset forgets the recorded changes, used when the database was reloaded.
+func (t *tx) reset() {
+	t.changes = nil
+	t.seen = map[string]bool{}
+}
+
+// save writes the touched records or the whole database and journals the
+// recorded changes as one operation.
+func (t *tx) save(summary string) error {
+	var refs []data.Ref
+	if t.records {
+		refs = refsOf(t.changes)
+	}
+	return data.Db.SaveChanges(refs, t.operation(summary))
+}
+
+// commit saves the database and journals the recorded changes.
+func (t *tx) commit(summary string) bool {
+	if !checkSave(t.save(summary)) {
+		return false
+	}
+	updateIndex(t.changes)
+	return true
+}
+
+// commitEdit saves an interactive change like saveEdit. apply has to touch
+// the records it changes, since it is repeated after a reload.
+func (t *tx) commitEdit(summary string, apply func() bool) bool {
+	saved := saveEdit(func() error {
+		return t.save(summary)
+	}, func() bool {
+		t.reset()
+		return apply()
+	})
+	if saved {
+		updateIndex(t.changes)
+	}
+	return saved
+}
+
+// refsOf returns the records of changes.
+func refsOf(changes []data.Change) []data.Ref {
+	refs := make([]data.Ref, 0, len(changes))
+	for _, c := range changes {
+		refs = append(refs, data.Ref{Table: c.Table, ID: c.ID})
+	}
+	return refs
+}
+
+// updateIndex updates the search index for the changed records.
+func updateIndex(changes []data.Change) {
+	if err := data.Db.UpdateIndex(refsOf(changes)); err != nil {
+		fmt.Printf("Warning: could not update search index: %v\n", err)
+	}
+}
+
+// operation returns the journal entry for the recorded changes, nil if
+// nothing changed. Unchanged records are left out. Tags created implicitly
+// by the edit form are not recorded.
+func (t *tx) operation(summary string) *data.Operation {
+	var changes []data.Change
+	for _, c := range t.changes {
+		c.After, _ = data.Db.GetRecord(c.Table, c.ID)
+		if !bytes.Equal(c.Before, c.After) {
+			changes = append(changes, c)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return &data.Operation{Op: t.op, Summary: summary, Changes: changes}
+}
+
+// Undo reverts the most recent operation that was not undone yet.
+func Undo() {
+	ops, err := data.ReadJournal()
+	if err != nil {
+		fmt.Printf("Error: could not read journal: %v\n", err)
+		return
+	}
+	undoable, _, _ := data.JournalStacks(ops)
+	if len(undoable) == 0 {
+		fmt.Println("Nothing to undo")
+		return
+	}
+	revert(undoable[len(undoable)-1], data.OpUndo)
+}
+
+// Redo repeats the most recently undone operation.
+func Redo() {
+	ops, err := data.ReadJournal()
+	if err != nil {
+		fmt.Printf("Error: could not read journal: %v\n", err)
+		return
+	}
+	_, redoable, _ := data.JournalStacks(ops)
+	if len(redoable) == 0 {
+		fmt.Println("Nothing to redo")
+		return
+	}
+	revert(redoable[len(redoable)-1], data.OpRedo)
+}
+
+// revert applies an operation backwards (undo) or forwards (redo) and journals it.
+func revert(op data.Operation, kind string) {
+	if err := data.Db.ApplyChanges(op.Changes, kind == data.OpUndo); err != nil {
+		fmt.Printf("Cannot %s \"%s\": %v\n", kind, op.Summary, err)
+		return
+	}
+	// an operation changes nothing but its records
+	if !checkSave(data.Db.SaveChanges(refsOf(op.Changes), &data.Operation{Op: kind, Summary: op.Summary, Ref: op.Seq})) {
+		return
+	}
+	updateIndex(op.Changes)
+	if kind == data.OpUndo {
+		fmt.Printf("Undone: %s\n", op.Summary)
+	} else {
+		fmt.Printf("Redone: %s\n", op.Summary)
+	}
+}
+
+// PrintJournal lists the most recent journal entries.
+func PrintJournal(count int) {
+	ops, err := data.ReadJournal()
+	if err != nil {
+		fmt.Printf("Error: could not read journal: %v\n", err)
+		return
+	}
+	if len(ops) == 0 {
+		fmt.Println("The journal is empty")
+		return
+	}
+	_, _, undone := data.JournalStacks(ops)
+	if count > 0 && len(ops) > count {
+		ops = ops[len(ops)-count:]
+	}
+	fmt.Println(terminal.GetHeadlineText(fmt.Sprintf("%5s %-16s %-8s %-50s", "Seq", "Time", "Op", "Summary")))
+	for _, op := range ops {
+		summary := op.Summary
+		switch {
+		case op.Ref > 0:
+			summary = fmt.Sprintf("#%d %s", op.Ref, op.Summary)
+		case undone[op.Seq]:
+			summary = terminal.GetStrikeTroughText(summary) + " (undone)"
+		}
+		fmt.Printf("%5d %-16s %-8s %s\n", op.Seq, terminal.GetTimeString(op.Time), op.Op, summary)
+	}
+}
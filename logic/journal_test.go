@@ -0,0 +1,224 @@
+package logic
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elsni/lagerator/data"
+)
+
+// TestUndoRedoMove verifies that a move can be undone and redone.
+func TestUndoRedoMove(t *testing.T) {
+	resetDb()
+	AddWarehouse("WH1")
+	SwitchWarehouse("WH1")
+	AddRoomToCurrentWarehouse("R1")
+	AddShelfToRoom("S1", "R1")
+	AddBoxToShelf("B1", "S1")
+	AddBoxToShelf("B2", "S1")
+	box1 := data.Db.Boxes[0]
+	box2 := data.Db.Boxes[1]
+	item := data.NewDataset[data.Item]("I1", data.Item{BoxId: box1.ID})
+	data.Db.Items.Add(item)
+
+	MoveItem(item.ID, strconv.FormatUint(uint64(box2.ID), 10))
+	out := captureOutput(t, Undo)
+	if !strings.Contains(out, "Undone: move item") {
+		t.Fatalf("expected undo message, got: %s", out)
+	}
+	if data.Db.Items[0].Data.BoxId != box1.ID {
+		t.Fatalf("expected item back in box %d", box1.ID)
+	}
+
+	out = captureOutput(t, Redo)
+	if !strings.Contains(out, "Redone: move item") {
+		t.Fatalf("expected redo message, got: %s", out)
+	}
+	if data.Db.Items[0].Data.BoxId != box2.ID {
+		t.Fatalf("expected item in box %d after redo", box2.ID)
+	}
+
+	out = captureOutput(t, Redo)
+	if !strings.Contains(out, "Nothing to redo") {
+		t.Fatalf("expected nothing to redo, got: %s", out)
+	}
+}
+
+// TestUndoAdd verifies that undoing an add removes the record and that a
+// new operation discards the redo history.
+func TestUndoAdd(t *testing.T) {
+	resetDb()
+	AddWarehouse("WH1")
+	AddCategory("Tools")
+	captureOutput(t, Undo)
+	if len(data.Db.Categories) != 0 {
+		t.Fatalf("expected category to be removed, got %+v", data.Db.Categories)
+	}
+
+	AddCategory("Food")
+	out := captureOutput(t, Redo)
+	if !strings.Contains(out, "Nothing to redo") {
+		t.Fatalf("expected redo history to be discarded, got: %s", out)
+	}
+
+	out = captureOutput(t, func() { PrintJournal(10) })
+	for _, expected := range []string{"add warehouse", "add category \"Tools\"", "(undone)", "add category \"Food\""} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("expected %q in journal, got: %s", expected, out)
+		}
+	}
+}
+
+// TestUndoRefusesChangedRecord verifies that later changes block an undo.
+func TestUndoRefusesChangedRecord(t *testing.T) {
+	resetDb()
+	cat := data.NewDataset[data.Category]("Tools", data.Category{})
+	data.Db.Categories.Add(cat)
+	AddTag("fragile", cat.ID)
+
+	// change the record behind the journal's back
+	data.Db.Categories[0].Name = "Renamed"
+	out := captureOutput(t, Undo)
+	if !strings.Contains(out, "Cannot undo") {
+		t.Fatalf("expected refusal, got: %s", out)
+	}
+	if data.GetTagList(data.Db.Categories[0].Tags) != "fragile" {
+		t.Fatalf("record must stay untouched when the undo is refused")
+	}
+}
+
+// TestUndoTagRemovesCreatedTag verifies that a tag created by AddTag is
+// part of the change and removed by its undo.
+func TestUndoTagRemovesCreatedTag(t *testing.T) {
+	resetDb()
+	cat := data.NewDataset[data.Category]("Tools", data.Category{})
+	data.Db.Categories.Add(cat)
+	captureOutput(t, func() { AddTag("fragile", cat.ID) })
+	out := captureOutput(t, func() { AddTag("fragile", cat.ID) })
+	if !strings.Contains(out, "already tagged") || len(data.Db.Tags) != 1 {
+		t.Fatalf("expected the second tag to be refused, got %d tags: %s", len(data.Db.Tags), out)
+	}
+
+	captureOutput(t, Undo)
+	if len(data.Db.Categories[0].Tags) != 0 || len(data.Db.Tags) != 0 {
+		t.Fatalf("expected tag and tagging to be undone, got %+v and %+v", data.Db.Categories[0].Tags, data.Db.Tags)
+	}
+}
+
+// TestSearchIndexFollowsChanges verifies that saved changes and their undo
+// update the search index file.
+func TestSearchIndexFollowsChanges(t *testing.T) {
+	resetDb()
+	AddWarehouse("WH1")
+	item := data.NewDataset[data.Item]("Drill", data.Item{})
+	data.Db.Items.Add(item)
+
+	captureOutput(t, func() { AddTag("kaputt", item.ID) })
+	if path, _ := data.IndexPath(); !exists(path) {
+		t.Fatal("expected the index to be written")
+	}
+	data.Db = data.NewDatabase()
+	if err := data.Db.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !data.Db.SearchIndex().Lookup("kaputt")[item.ID] {
+		t.Fatal("expected the tag to be indexed")
+	}
+
+	captureOutput(t, Undo)
+	if data.Db.SearchIndex().Lookup("kaputt")[item.ID] {
+		t.Fatal("expected the undone tag to be gone from the index")
+	}
+}
+
+// exists reports whether a file exists.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// TestUndoSwitchWarehouse verifies that switching the warehouse is journaled.
+func TestUndoSwitchWarehouse(t *testing.T) {
+	resetDb()
+	AddWarehouse("WH1")
+	AddWarehouse("WH2")
+	captureOutput(t, func() { SwitchWarehouse("WH1") })
+	first := data.Db.CurrentWarehouse
+	captureOutput(t, func() { SwitchWarehouse("WH2") })
+
+	out := captureOutput(t, Undo)
+	if !strings.Contains(out, "Undone: switch to warehouse \"WH2\"") {
+		t.Fatalf("expected undo message, got: %s", out)
+	}
+	if data.Db.CurrentWarehouse != first {
+		t.Fatalf("expected warehouse %d after undo, got %d", first, data.Db.CurrentWarehouse)
+	}
+}
+
+// TestJournalSeqAfterTornLine verifies that numbering continues after the
+// last complete entry, even when it is larger than the part read first.
+func TestJournalSeqAfterTornLine(t *testing.T) {
+	resetDb()
+	path, err := data.JournalPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	big := data.Operation{Op: "add", Summary: strings.Repeat("x", 200*1024)}
+	for i := 0; i < 3; i++ {
+		if err := data.AppendJournal(&big); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"seq":99,"op":"ad`)
+	f.Close()
+
+	op := data.Operation{Op: "add", Summary: "next"}
+	if err := data.AppendJournal(&op); err != nil {
+		t.Fatal(err)
+	}
+	if op.Seq != 4 {
+		t.Fatalf("expected seq 4, got %d", op.Seq)
+	}
+	ops, err := data.ReadJournal()
+	if err != nil || len(ops) != 4 || ops[3].Summary != "next" {
+		t.Fatalf("expected the new entry on its own line, got %d entries, %v", len(ops), err)
+	}
+}
+
+// TestJournalWaitsForLock verifies that journal entries are only numbered
+// and written while the database lock is held.
+func TestJournalWaitsForLock(t *testing.T) {
+	resetDb()
+	path, err := data.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, err := data.LockPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- data.AppendJournal(&data.Operation{Op: "add", Summary: "waiting"})
+	}()
+	select {
+	case <-done:
+		t.Fatal("expected the journal to wait for the lock")
+	case <-time.After(100 * time.Millisecond):
+	}
+	lock.Unlock()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	ops, err := data.ReadJournal()
+	if err != nil || len(ops) != 1 || ops[0].Seq != 1 {
+		t.Fatalf("unexpected journal %+v, %v", ops, err)
+	}
+}
@@ -0,0 +1,104 @@
+package logic
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/elsni/lagerator/data"
+)
+
+// Take removes n pieces of an item and records it in the item's ledger.
+func Take(itemid uint32, n int, reason string) {
+	adjustAmount("take", itemid, -n, reason)
+}
+
+// Put adds n pieces to an item and records it in the item's ledger.
+func Put(itemid uint32, n int, reason string) {
+	adjustAmount("put", itemid, n, reason)
+}
+
+// adjustAmount changes the amount of an item, refusing to go below zero.
+func adjustAmount(op string, itemid uint32, change int, reason string) {
+	if change == 0 {
+		fmt.Println("Error: the number of pieces must not be 0")
+		return
+	}
+	item, saved := updateItem(op, itemid, func(name string) string {
+		return fmt.Sprintf("%s %d of \"%s\"", op, max(change, -change), name)
+	}, func(set *data.Dataset[data.Item]) error {
+		if err := set.Data.Adjust(change, reason); err != nil {
+			return fmt.Errorf("cannot take %d of \"%s\", %w", -change, set.Name, err)
+		}
+		return nil
+	})
+	if !saved {
+		return
+	}
+	if change < 0 {
+		fmt.Printf("Took %d of \"%s\", %d left\n", -change, item.Name, item.Data.Amount)
+	} else {
+		fmt.Printf("Put %d of \"%s\", %d in stock\n", change, item.Name, item.Data.Amount)
+	}
+}
+
+// updateItem changes an item with apply and journals it as op. summary
+// returns the journal summary for the item name. apply must not change
+// anything on error.
+func updateItem(op string, itemid uint32, summary func(name string) string, apply func(set *data.Dataset[data.Item]) error) (data.Dataset[data.Item], bool) {
+	var item data.Dataset[data.Item]
+	found, ok := data.Db.Items.GetPtr(itemid)
+	if !ok {
+		fmt.Printf("No item with ID %d found.\n", itemid)
+		return item, false
+	}
+	t := beginRecords(op)
+	saved := t.commitEdit(summary(found.Name), func() bool {
+		set, ok := data.Db.Items.GetPtr(itemid)
+		if !ok {
+			fmt.Printf("No item with ID %d found.\n", itemid)
+			return false
+		}
+		touch(t, &data.Db.Items, itemid)
+		if err := apply(set); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return false
+		}
+		set.Updated = time.Now().Unix()
+		item = *set
+		return true
+	})
+	return item, saved
+}
+
+// ShowLedger prints the ledger of an item from since on.
+func ShowLedger(itemid uint32, since time.Time) {
+	set, ok := data.Db.Items.GetPtr(itemid)
+	if !ok {
+		fmt.Printf("No item with ID %d found.\n", itemid)
+		return
+	}
+	entries := set.Data.LedgerSince(since.Unix())
+	if len(entries) == 0 {
+		fmt.Printf("No ledger entries for \"%s\"\n", set.Name)
+		return
+	}
+	fmt.Printf("Ledger of \"%s\", %d in stock\n", set.Name, set.Data.Amount)
+	data.PrintLedger(entries)
+}
+
+// keepItemState carries the ledger and loan of the stored item over to an
+// item changed in the edit form and records an edited amount in the ledger.
+func keepItemState[T data.CustomData](tbl *data.DataTable[T], set *data.Dataset[T]) {
+	item, ok := any(&set.Data).(*data.Item)
+	stored, found := tbl.GetPtr(set.ID)
+	if !ok || !found {
+		return
+	}
+	old := any(stored.Data).(data.Item)
+	item.Ledger = slices.Clone(old.Ledger)
+	item.Loan = old.Loan
+	if item.Amount != old.Amount {
+		item.Ledger = append(item.Ledger, data.LedgerEntry{Time: time.Now().Unix(), Change: item.Amount - old.Amount, Amount: item.Amount, Reason: "edited"})
+	}
+}
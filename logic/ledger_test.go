@@ -0,0 +1,80 @@
+package logic
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elsni/lagerator/data"
+)
+
+// TestTakePut verifies amount changes, the ledger and the refusal to go negative.
+func TestTakePut(t *testing.T) {
+	resetDb()
+	item := data.NewDataset("Drill", data.Item{Amount: 3})
+	data.Db.Items.Add(item)
+
+	out := captureOutput(t, func() { Take(item.ID, 2, "workshop") })
+	if !strings.Contains(out, "Took 2 of \"Drill\", 1 left") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	out = captureOutput(t, func() { Take(item.ID, 2, "") })
+	if !strings.Contains(out, "only 1 left") || data.Db.Items[0].Data.Amount != 1 {
+		t.Fatalf("expected take to be refused, got: %s", out)
+	}
+	captureOutput(t, func() { Put(item.ID, 4, "") })
+
+	ledger := data.Db.Items[0].Data.Ledger
+	if data.Db.Items[0].Data.Amount != 5 || len(ledger) != 2 {
+		t.Fatalf("unexpected item: %+v", data.Db.Items[0].Data)
+	}
+	if ledger[0].Change != -2 || ledger[0].Amount != 1 || ledger[0].Reason != "workshop" || ledger[1].Change != 4 {
+		t.Fatalf("unexpected ledger: %+v", ledger)
+	}
+
+	out = captureOutput(t, func() { ShowLedger(item.ID, time.Now().Add(time.Hour)) })
+	if !strings.Contains(out, "No ledger entries") {
+		t.Fatalf("expected no entries in the future, got: %s", out)
+	}
+	out = captureOutput(t, func() { ShowLedger(item.ID, time.Time{}) })
+	if !strings.Contains(out, "workshop") {
+		t.Fatalf("expected ledger, got: %s", out)
+	}
+
+	out = captureOutput(t, Undo)
+	if data.Db.Items[0].Data.Amount != 1 || len(data.Db.Items[0].Data.Ledger) != 1 {
+		t.Fatalf("expected undo to revert the put, got %+v: %s", data.Db.Items[0].Data, out)
+	}
+}
+
+// TestTakePutSQLite verifies that take and put save the item to a SQLite database.
+func TestTakePutSQLite(t *testing.T) {
+	data.SetPath(filepath.Join(t.TempDir(), "lgrtdata.sqlite"))
+	defer data.SetPath("")
+	defer data.CloseStore()
+	resetDb()
+	item := data.NewDataset("Drill", data.Item{Amount: 3})
+	data.Db.Items.Add(item)
+	if err := data.Db.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	captureOutput(t, func() { Take(item.ID, 2, "") })
+	captureOutput(t, func() { Put(item.ID, 5, "") })
+	loaded := data.NewDatabase()
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded.Items) != 1 || loaded.Items[0].Data.Amount != 6 || len(loaded.Items[0].Data.Ledger) != 2 {
+		t.Fatalf("unexpected stored items: %+v", loaded.Items)
+	}
+
+	captureOutput(t, Undo)
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Items[0].Data.Amount != 1 {
+		t.Fatalf("expected undo to be saved, got %+v", loaded.Items[0].Data)
+	}
+}
@@ -0,0 +1,100 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/elsni/lagerator/data"
+	"github.com/elsni/lagerator/terminal"
+)
+
+// Lend records that person borrowed an item. due is the day the item is
+// expected back, the zero time means no due date.
+func Lend(itemid uint32, person string, due time.Time) {
+	item, saved := updateItem("lend", itemid, func(name string) string {
+		return fmt.Sprintf("lend \"%s\" to %s", name, person)
+	}, func(set *data.Dataset[data.Item]) error {
+		if set.Data.Loan != nil {
+			return fmt.Errorf("\"%s\" is already lent to %s, return it first", set.Name, set.Data.Loan.Borrower)
+		}
+		loan := &data.Loan{Borrower: person, Since: time.Now().Unix()}
+		if !due.IsZero() {
+			// due at the end of the day
+			loan.Due = time.Date(due.Year(), due.Month(), due.Day(), 23, 59, 59, 0, time.Local).Unix()
+		}
+		set.Data.Loan = loan
+		return nil
+	})
+	if !saved {
+		return
+	}
+	if item.Data.Loan.Due != 0 {
+		fmt.Printf("Lent \"%s\" to %s until %s\n", item.Name, person, terminal.GetDateString(item.Data.Loan.Due))
+	} else {
+		fmt.Printf("Lent \"%s\" to %s\n", item.Name, person)
+	}
+}
+
+// Return records that a lent item is back.
+func Return(itemid uint32) {
+	var borrower string
+	item, saved := updateItem("return", itemid, func(name string) string {
+		return fmt.Sprintf("return \"%s\"", name)
+	}, func(set *data.Dataset[data.Item]) error {
+		if set.Data.Loan == nil {
+			return fmt.Errorf("\"%s\" is not lent", set.Name)
+		}
+		borrower = set.Data.Loan.Borrower
+		set.Data.Loan = nil
+		return nil
+	})
+	if saved {
+		fmt.Printf("\"%s\" is back from %s\n", item.Name, borrower)
+	}
+}
+
+// ListLent prints the lent items, the ones due first. Overdue loans are highlighted.
+func ListLent() {
+	var lent []data.Dataset[data.Item]
+	for _, set := range data.Db.Items {
+		if !set.Deleted && set.Data.Loan != nil && data.InScope(set) {
+			lent = append(lent, set)
+		}
+	}
+	// loans without due date last, then by lending date
+	sort.SliceStable(lent, func(i, j int) bool {
+		a, b := lent[i].Data.Loan, lent[j].Data.Loan
+		if (a.Due == 0) != (b.Due == 0) {
+			return b.Due == 0
+		}
+		if a.Due != b.Due {
+			return a.Due < b.Due
+		}
+		return a.Since < b.Since
+	})
+	if data.Output != data.OutputText {
+		data.WriteSets(lent)
+		return
+	}
+	if len(lent) == 0 {
+		fmt.Println("No items are lent")
+		return
+	}
+	fmt.Println(terminal.GetHeadlineText(fmt.Sprintf("%5s %-30s %-20s %-10s %-10s", "ID", "Name", "Lent to", "Since", "Due")))
+	overdue := 0
+	for _, set := range lent {
+		loan := set.Data.Loan
+		due := ""
+		if loan.Due != 0 {
+			due = terminal.GetDateString(loan.Due)
+		}
+		line := fmt.Sprintf("%5d %-30s %-20s %-10s %-10s", set.ID, set.GetPrintName(30), loan.Borrower, terminal.GetDateString(loan.Since), due)
+		if loan.Overdue() {
+			line = terminal.GetWarningText(line + " overdue")
+			overdue++
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("%d items lent, %d overdue\n", len(lent), overdue)
+}
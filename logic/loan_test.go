@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elsni/lagerator/data"
+)
+
+// TestLendReturn verifies lending, the overdue list and returning.
+func TestLendReturn(t *testing.T) {
+	resetDb()
+	drill := data.NewDataset("Drill", data.Item{Amount: 1})
+	saw := data.NewDataset("Saw", data.Item{Amount: 1})
+	data.Db.Items.Add(drill)
+	data.Db.Items.Add(saw)
+
+	captureOutput(t, func() { Lend(drill.ID, "Anna", time.Now().AddDate(0, 0, -2)) })
+	captureOutput(t, func() { Lend(saw.ID, "Ben", time.Time{}) })
+	out := captureOutput(t, func() { Lend(drill.ID, "Ben", time.Time{}) })
+	if !strings.Contains(out, "already lent to Anna") {
+		t.Fatalf("expected second loan to be refused, got: %s", out)
+	}
+
+	out = captureOutput(t, ListLent)
+	if !strings.Contains(out, "2 items lent, 1 overdue") || strings.Index(out, "Drill") > strings.Index(out, "Saw") {
+		t.Fatalf("unexpected list: %s", out)
+	}
+	out = captureOutput(t, func() { data.Db.Items[0].Show() })
+	if !strings.Contains(out, "Anna since") || !strings.Contains(out, "overdue") {
+		t.Fatalf("expected borrower in details, got: %s", out)
+	}
+
+	out = captureOutput(t, func() { Return(drill.ID) })
+	if !strings.Contains(out, "\"Drill\" is back from Anna") || data.Db.Items[0].Data.Loan != nil {
+		t.Fatalf("unexpected return: %s", out)
+	}
+	out = captureOutput(t, func() { Return(drill.ID) })
+	if !strings.Contains(out, "is not lent") {
+		t.Fatalf("expected error for an item that is not lent, got: %s", out)
+	}
+}
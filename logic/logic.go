@@ -1,6 +1,7 @@
 package logic
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/elsni/lagerator/data"
+	"github.com/elsni/lagerator/id"
 	"github.com/elsni/lagerator/ui"
 )
 
@@ -75,6 +77,146 @@ func CurrentWarehouseExists() bool {
 	return ok
 }
 
+// saveDb writes the database and reports a failure to the user.
+func saveDb() bool {
+	return checkSave(data.Db.Save())
+}
+
+// checkSave reports a failed save to the user, it returns whether the
+// database was saved.
+func checkSave(err error) bool {
+	if errors.Is(err, data.ErrJournal) {
+		fmt.Printf("Warning: %v\n", err)
+		return true
+	}
+	if errors.Is(err, data.ErrConflict) {
+		fmt.Println("Error: the database was changed by another lgrt process, nothing was saved. Please run the command again.")
+		return false
+	}
+	if err != nil {
+		fmt.Printf("Error: could not save database: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// saveEdit applies an interactive change and saves it. If another process
+// saved the database in the meantime, the user may reload it and the change
+// is applied again to the fresh data. apply returns false if that is impossible.
+// save writes the change, like data.Db.Save.
+func saveEdit(save func() error, apply func() bool) bool {
+	if !apply() {
+		return false
+	}
+	for {
+		err := save()
+		if !errors.Is(err, data.ErrConflict) {
+			return checkSave(err)
+		}
+		if !ui.Alert("The database changed underneath you. Reload it and apply your change?") {
+			fmt.Println("Your change was discarded")
+			return false
+		}
+		if err := data.Db.Load(); err != nil {
+			fmt.Printf("Error: could not reload database: %v\n", err)
+			return false
+		}
+		if !apply() {
+			return false
+		}
+	}
+}
+
+// editSet saves and journals a dataset changed in the edit form.
+func editSet[T data.CustomData](tbl *data.DataTable[T], set data.Dataset[T]) bool {
+	tagnames := data.GetTagList(set.Tags)
+	objname := strings.Split(fmt.Sprintf("%T", *new(T)), ".")
+	t := begin("edit")
+	return t.commitEdit(fmt.Sprintf("edit %s \"%s\"", strings.ToLower(objname[1]), set.Name), func() bool {
+		// tags created by the form are gone after a reload, resolve them again
+		set.Tags = data.GetTagIds(tagnames)
+		keepItemState(tbl, &set)
+		touch(t, tbl, set.ID)
+		return replaceSet(tbl, set)
+	})
+}
+
+// replaceSet replaces the dataset with the same id, reporting a deleted one.
+func replaceSet[T data.CustomData](tbl *data.DataTable[T], set data.Dataset[T]) bool {
+	idx := tbl.GetIdx(set.ID)
+	if idx < 0 {
+		fmt.Printf("\"%s\" was deleted by another lgrt process\n", set.Name)
+		return false
+	}
+	tbl.Replace(idx, set)
+	return true
+}
+
+// LoadDatabase loads the database and offers the newest valid backup
+// when the file is corrupted. It returns false if no usable data was loaded.
+func LoadDatabase() bool {
+	err := data.Db.Load()
+	if err == nil {
+		return true
+	}
+	var loadErr *data.LoadError
+	if !errors.As(err, &loadErr) {
+		fmt.Printf("Error: could not load database: %v\n", err)
+		return false
+	}
+	fmt.Printf("Error: database is corrupted: %v\n", err)
+	n, ok := data.NewestValidBackup()
+	if !ok {
+		fmt.Println("No valid backup found. Run \"lgrt repair\" to salvage the data.")
+		return false
+	}
+	if !ui.Alert(fmt.Sprintf("Database is corrupted. Load backup generation %d?", n)) {
+		fmt.Println("Run \"lgrt repair\" to salvage the data.")
+		return false
+	}
+	if err := data.Db.LoadBackup(n); err != nil {
+		fmt.Printf("Error: could not load backup %d: %v\n", n, err)
+		return false
+	}
+	if err := data.Db.ForceSave(); err != nil {
+		fmt.Printf("Error: could not save database: %v\n", err)
+		return false
+	}
+	fmt.Printf("Restored backup generation %d, the corrupted file was kept as backup 1\n", n)
+	return true
+}
+
+// Repair salvages a corrupted database and prints what was dropped.
+func Repair() {
+	report, err := data.Db.Repair()
+	if err != nil && report.Tables == nil {
+		fmt.Printf("Error: could not read database: %v\n", err)
+		return
+	}
+	if report.Clean {
+		fmt.Println("Database is fine, nothing to repair")
+		return
+	}
+	for _, note := range report.Notes {
+		fmt.Println(note)
+	}
+	for _, table := range report.Tables {
+		switch {
+		case table.Missing:
+			fmt.Printf("%-12s lost completely\n", table.Name)
+		case table.Truncated:
+			fmt.Printf("%-12s kept %d, dropped %d, rest of the table was truncated\n", table.Name, table.Kept, table.Dropped)
+		default:
+			fmt.Printf("%-12s kept %d, dropped %d\n", table.Name, table.Kept, table.Dropped)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error: could not save repaired database: %v\n", err)
+		return
+	}
+	fmt.Println("Repaired database saved, the corrupted file was kept as backup 1")
+}
+
 // SwitchWarehouse selects the active warehouse by name.
 func SwitchWarehouse(whname string) {
 	index, id := data.Db.Warehouses.GetDataByName(whname)
@@ -82,8 +224,12 @@ func SwitchWarehouse(whname string) {
 		fmt.Printf("warehouse \"%s\" does not exist\n", whname)
 		return
 	}
+	t := begin("switch")
+	t.touchValue("currentWarehouseid")
 	data.Db.CurrentWarehouse = id
-	data.Db.Save()
+	if !t.commit(fmt.Sprintf("switch to warehouse \"%s\"", whname)) {
+		return
+	}
 	fmt.Printf("Warehouse \"%s\" is now active\n", whname)
 }
 
@@ -95,8 +241,12 @@ func AddWarehouse(whname string) {
 		return
 	}
 	nwh := data.NewDataset[data.Warehouse](whname, data.Warehouse{})
+	t := begin("add")
+	touch(t, &data.Db.Warehouses, nwh.ID)
 	data.Db.Warehouses.Add(nwh)
-	data.Db.Save()
+	if !t.commit(fmt.Sprintf("add warehouse \"%s\"", whname)) {
+		return
+	}
 
 	fmt.Printf("Added warehouse \"%s\" with ID %d\n", whname, nwh.ID)
 }
@@ -109,8 +259,12 @@ func AddCategory(cname string) {
 		return
 	}
 	nc := data.NewDataset[data.Category](cname, data.Category{})
+	t := begin("add")
+	touch(t, &data.Db.Categories, nc.ID)
 	data.Db.Categories.Add(nc)
-	data.Db.Save()
+	if !t.commit(fmt.Sprintf("add category \"%s\"", cname)) {
+		return
+	}
 
 	fmt.Printf("Added Category \"%s\" with ID %d\n", cname, nc.ID)
 }
@@ -132,8 +286,12 @@ func AddRoomToCurrentWarehouse(roomname string) {
 		return
 	}
 	nroom := data.NewDataset[data.Room](roomname, data.Room{WarehouseId: data.Db.CurrentWarehouse})
+	t := begin("add")
+	touch(t, &data.Db.Rooms, nroom.ID)
 	data.Db.Rooms.Add(nroom)
-	data.Db.Save()
+	if !t.commit(fmt.Sprintf("add room \"%s\"", roomname)) {
+		return
+	}
 
 	fmt.Printf("Added room \"%s\" with id %d to warehouse \"%s\"\n", roomname, nroom.ID, wh.Name)
 }
@@ -149,8 +307,12 @@ func AddShelfToRoom(shelfname string, roomname string) {
 		return
 	}
 	newshelf := data.NewDataset[data.Shelf](shelfname, data.Shelf{RoomId: data.Db.Rooms[idx].ID})
+	t := begin("add")
+	touch(t, &data.Db.Shelves, newshelf.ID)
 	data.Db.Shelves.Add(newshelf)
-	data.Db.Save()
+	if !t.commit(fmt.Sprintf("add shelf \"%s\"", shelfname)) {
+		return
+	}
 	fmt.Printf("Added shelf \"%s\" with id %d to room \"%s\"\n", shelfname, newshelf.ID, data.Db.Rooms[idx].Name)
 }
 
@@ -166,8 +328,12 @@ func AddBoxToShelf(boxname string, shelfname string) {
 	}
 
 	newbox := data.NewDataset[data.Box](boxname, data.Box{ShelfId: data.Db.Shelves[idx].ID})
+	t := begin("add")
+	touch(t, &data.Db.Boxes, newbox.ID)
 	data.Db.Boxes.Add(newbox)
-	data.Db.Save()
+	if !t.commit(fmt.Sprintf("add box \"%s\"", boxname)) {
+		return
+	}
 	fmt.Printf("Added Box \"%s\" with id %d to shelf \"%s\"\n", boxname, newbox.ID, data.Db.Shelves[idx].Name)
 }
 
@@ -179,8 +345,15 @@ func AddItems(boxnameorid string) {
 	if index == -1 {
 		return
 	}
+	boxid := data.Db.Boxes[index].ID
 	for {
-		item := data.NewDataset[data.Item]("", data.Item{BoxId: data.Db.Boxes[index].ID, CategoryId: oldcategory, Location: oldlocation, Amount: 1})
+		// the box index may change when the database is reloaded after a conflict
+		index = data.Db.Boxes.GetIdx(boxid)
+		if index == -1 {
+			fmt.Println("The box was deleted by another lgrt process")
+			return
+		}
+		item := data.NewDataset[data.Item]("", data.Item{BoxId: boxid, CategoryId: oldcategory, Location: oldlocation, Amount: 1})
 
 		// build [][]IdOptions for populating the reference dropdowns
 		idopts := ToDropDownOpts(data.GetBoxNamesforShelf(&data.Db.Boxes, data.Db.Boxes[index].Data.ShelfId))
@@ -189,10 +362,22 @@ func AddItems(boxnameorid string) {
 		item, saved := ui.EditItem(item, idopts, " Add ")
 		oldcategory = item.Data.CategoryId
 		oldlocation = item.Data.Location
-		if saved {
+		if !saved {
+			return
+		}
+		tagnames := data.GetTagList(item.Tags)
+		t := begin("add")
+		added := t.commitEdit(fmt.Sprintf("add item \"%s\"", item.Name), func() bool {
+			// ids handed out by another process may collide after a reload
+			if item.ID <= data.Db.FindLastId() {
+				item.ID = id.IdSource.GetNewId()
+			}
+			item.Tags = data.GetTagIds(tagnames)
+			touch(t, &data.Db.Items, item.ID)
 			data.Db.Items.Add(item)
-			data.Db.Save()
-		} else {
+			return true
+		})
+		if !added {
 			return
 		}
 	}
@@ -209,9 +394,13 @@ func MoveItem(itemid uint32, boxnameorid string) {
 	if boxidx == -1 {
 		return
 	}
+	t := beginRecords("move")
+	touch(t, &data.Db.Items, itemid)
 	data.Db.Items[itemidx].Data.BoxId = data.Db.Boxes[boxidx].ID
 	data.Db.Items[itemidx].Updated = time.Now().Unix()
-	data.Db.Save()
+	if !t.commit(fmt.Sprintf("move item \"%s\" to \"%s\"", data.Db.Items[itemidx].Name, data.Db.Boxes[boxidx].Name)) {
+		return
+	}
 	fmt.Printf("Moved Item \"%s\" to \"%s\"\n", data.Db.Items[itemidx].Name, data.Db.Boxes[boxidx].Name)
 }
 
@@ -226,9 +415,13 @@ func MoveBox(boxid uint32, shelfnameorid string) {
 	if shelfidx == -1 {
 		return
 	}
+	t := beginRecords("move")
+	touch(t, &data.Db.Boxes, boxid)
 	data.Db.Boxes[boxidx].Data.ShelfId = data.Db.Shelves[shelfidx].ID
 	data.Db.Boxes[boxidx].Updated = time.Now().Unix()
-	data.Db.Save()
+	if !t.commit(fmt.Sprintf("move box \"%s\" to \"%s\"", data.Db.Boxes[boxidx].Name, data.Db.Shelves[shelfidx].Name)) {
+		return
+	}
 	fmt.Printf("Moved box \"%s\" to \"%s\"\n", data.Db.Boxes[boxidx].Name, data.Db.Shelves[shelfidx].Name)
 }
 
@@ -341,28 +534,162 @@ func EditSet[T data.CustomData](tbl *data.DataTable[T], setname string, tablenam
 	}
 	set, saved := ui.EditItem((*tbl)[idx], GetDropDownOpts((*tbl)[idx].ID), " Edit ")
 	if saved {
-		(*tbl)[idx] = set
-		data.Db.Save()
+		editSet(tbl, set)
 	}
 }
 
+// DeleteOptions says what happens to the objects contained in or
+// referring to a deleted object. Without an option the user is asked.
+type DeleteOptions struct {
+	Cascade bool
+	// Reparent is the name or id of the object that takes over the contents
+	Reparent string
+}
+
 // DeleteSet deletes a selected set after confirmation.
-func DeleteSet[T data.CustomData](tbl *data.DataTable[T], setname string, tablename string) {
+func DeleteSet[T data.CustomData](tbl *data.DataTable[T], setname string, tablename string, opts DeleteOptions) {
 	idx := SelectSet(tbl, setname, tablename, "delete")
 	if idx < 0 {
 		return
 	}
 
-	DeleteSetId(tbl, idx, tablename)
+	DeleteSetId(tbl, idx, tablename, opts)
+}
+
+// DeleteSetId deletes a set by index. Contained objects are deleted as
+// well or moved to another parent, as chosen by opts or by the user.
+func DeleteSetId[T data.CustomData](tbl *data.DataTable[T], idx int, tablename string, opts DeleteOptions) {
+	set := (*tbl)[idx]
+	ref := data.Ref{Table: data.Db.TableName(tbl), ID: set.ID}
+	kind := strings.ToLower(tablename)
+	var newparent data.Dataset[T]
+	if opts.Reparent != "" {
+		var ok bool
+		if newparent, ok = newParent(tbl, set, opts.Reparent, tablename); !ok {
+			fmt.Println("Nothing deleted")
+			return
+		}
+	}
+	descendants := data.Db.Descendants(ref)
+	children := data.Db.LiveChildren(ref)
+	// the items of a category are kept without category unless they are moved
+	if len(descendants) == 0 && (ref.Table != "categories" || opts.Reparent == "" || len(children) == 0) {
+		question := fmt.Sprintf("Do you really want to delete %s \"%s\"?", kind, set.Name)
+		if len(children) > 0 {
+			question += fmt.Sprintf(" Its %s will have no %s.", describeRefs(children), kind)
+		}
+		// --cascade on a category confirms keeping its items, there is nothing else it applies to
+		if !(opts.Cascade && len(children) > 0) && !ui.Alert(question) {
+			return
+		}
+		t := begin("delete")
+		for _, child := range children {
+			t.touchRef(child)
+			data.Db.Reparent(child, ref, 0)
+		}
+		touch(t, tbl, set.ID)
+		tbl.Delete(set.ID)
+		if !t.commit(fmt.Sprintf("delete %s \"%s\"", kind, set.Name)) {
+			return
+		}
+		fmt.Printf("%s \"%s\" deleted\n", tablename, set.Name)
+		if len(children) > 0 {
+			fmt.Printf("Its %s have no %s now\n", describeRefs(children), kind)
+		}
+		return
+	}
+
+	contents := describeRefs(descendants)
+	if !opts.Cascade && opts.Reparent == "" {
+		fmt.Printf("%s \"%s\" contains %s\n", tablename, set.Name, contents)
+		switch ui.Choose(fmt.Sprintf("%s \"%s\" contains %s.", tablename, set.Name, contents), []string{"Delete all", "Move contents", "Cancel"}) {
+		case 0:
+			opts.Cascade = true
+		case 1:
+			var ok bool
+			if opts.Reparent = selectNewParent(tbl, set.ID); opts.Reparent != "" {
+				newparent, ok = newParent(tbl, set, opts.Reparent, tablename)
+			}
+			if !ok {
+				fmt.Println("Nothing deleted")
+				return
+			}
+		default:
+			fmt.Println("Nothing deleted")
+			return
+		}
+	}
+
+	t := begin("delete")
+	summary := ""
+	if opts.Cascade {
+		for _, d := range descendants {
+			t.touchRef(d)
+			data.Db.Delete(d)
+		}
+		summary = fmt.Sprintf("delete %s \"%s\" and %s", kind, set.Name, contents)
+	} else {
+		for _, child := range children {
+			t.touchRef(child)
+			data.Db.Reparent(child, ref, newparent.ID)
+		}
+		summary = fmt.Sprintf("delete %s \"%s\", moving %s to \"%s\"", kind, set.Name, describeRefs(children), newparent.Name)
+	}
+	touch(t, tbl, set.ID)
+	tbl.Delete(set.ID)
+	if !t.commit(summary) {
+		return
+	}
+	fmt.Println(strings.ToUpper(summary[:1]) + summary[1:])
+}
+
+// newParent resolves the set the contents of a deleted set are moved to.
+// It refuses the deleted set itself.
+func newParent[T data.CustomData](tbl *data.DataTable[T], set data.Dataset[T], name string, tablename string) (data.Dataset[T], bool) {
+	idx := SelectSet(tbl, name, tablename, "move the contents to")
+	if idx < 0 {
+		return data.Dataset[T]{}, false
+	}
+	if (*tbl)[idx].ID == set.ID {
+		fmt.Printf("Cannot move the contents of %s \"%s\" to itself\n", strings.ToLower(tablename), set.Name)
+		return data.Dataset[T]{}, false
+	}
+	return (*tbl)[idx], true
 }
 
-// DeleteSetId deletes a set by index after confirmation.
-func DeleteSetId[T data.CustomData](tbl *data.DataTable[T], idx int, tablename string) {
-	if ui.Alert(fmt.Sprintf("Do you really want to delete %s \"%s\"?", strings.ToLower(tablename), (*tbl)[idx].Name)) {
-		tbl.Delete((*tbl)[idx].ID)
-		data.Db.Save()
-		fmt.Printf("%s \"%s\" deleted\n", tablename, (*tbl)[idx].Name)
+// describeRefs counts records per table, e.g. "2 shelves, 5 items".
+func describeRefs(refs []data.Ref) string {
+	counts := map[string]int{}
+	for _, ref := range refs {
+		counts[ref.Table]++
+	}
+	var parts []string
+	for _, table := range data.TableOrder {
+		if counts[table] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[table], table))
+		}
 	}
+	return strings.Join(parts, ", ")
+}
+
+// selectNewParent lets the user pick another live set of the table and
+// returns its id as string, or "" if nothing was picked.
+func selectNewParent[T data.CustomData](tbl *data.DataTable[T], exclude uint32) string {
+	var sets []data.Dataset[T]
+	for _, set := range *tbl {
+		if !set.Deleted && set.ID != exclude {
+			sets = append(sets, set)
+		}
+	}
+	if len(sets) == 0 {
+		fmt.Println("There is nothing to move the contents to")
+		return ""
+	}
+	idx := ui.SelectItem(sets, "move the contents to")
+	if idx < 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(sets[idx].ID), 10)
 }
 
 // ShowSet prints details for the selected set or id.
@@ -380,6 +707,8 @@ func ShowSet[T data.CustomData](tbl *data.DataTable[T], setname string, tablenam
 			return
 		}
 		(*idset).Show()
+	} else if data.Output != data.OutputText {
+		data.WriteSets(sets)
 	} else {
 
 		for _, set := range sets {
@@ -388,17 +717,27 @@ func ShowSet[T data.CustomData](tbl *data.DataTable[T], setname string, tablenam
 	}
 }
 
-// AddTagById attaches a tag to a dataset at the given index.
-func AddTagById[T data.CustomData](tbl *data.DataTable[T], idx int, tagid uint32, tagname string) bool {
+// AddTagById attaches a tag to a dataset at the given index. A missing tag
+// is created as part of the change.
+func AddTagById[T data.CustomData](tbl *data.DataTable[T], idx int, tagname string) bool {
 	objname := strings.Split(fmt.Sprintf("%T", *new(T)), ".")
-	if slices.Contains((*tbl)[idx].Tags, tagid) {
+	tagid, found := data.Db.Tags.GetFirstOccurance(tagname)
+	if found && slices.Contains((*tbl)[idx].Tags, tagid) {
 		fmt.Printf("%s \"%s\" is already tagged with \"%s\"", objname[1], (*tbl)[idx].Name, tagname)
 		return false
 	}
+	t := beginRecords("tag")
+	if !found {
+		tagid = data.Db.Tags.AddSimple(tagname)
+		t.created(data.Ref{Table: "tags", ID: tagid})
+	}
+	touch(t, tbl, (*tbl)[idx].ID)
 	(*tbl)[idx].Tags = append((*tbl)[idx].Tags, tagid)
 	(*tbl)[idx].Updated = time.Now().Unix()
+	if !t.commit(fmt.Sprintf("tag %s \"%s\" with \"%s\"", strings.ToLower(objname[1]), (*tbl)[idx].Name, tagname)) {
+		return false
+	}
 	fmt.Printf("Added Tag \"%s\" to %s \"%s\"", tagname, objname[1], (*tbl)[idx].Name)
-	data.Db.Save()
 	return true
 }
 
@@ -417,34 +756,33 @@ func RemoveTagById[T data.CustomData](tbl *data.DataTable[T], idx int, tagid uin
 		}
 	}
 
+	t := beginRecords("untag")
+	touch(t, tbl, (*tbl)[idx].ID)
 	(*tbl)[idx].Tags = nt
 	(*tbl)[idx].Updated = time.Now().Unix()
+	if !t.commit(fmt.Sprintf("remove tag \"%s\" from %s \"%s\"", tagname, strings.ToLower(objname[1]), (*tbl)[idx].Name)) {
+		return false
+	}
 	fmt.Printf("Removed Tag \"%s\" from %s \"%s\"", tagname, objname[1], (*tbl)[idx].Name)
-	data.Db.Save()
 	return true
 }
 
 // AddTag adds a tag to the object identified by id.
 func AddTag(tagname string, id uint32) {
-	tagid, found := data.Db.Tags.GetFirstOccurance(tagname)
-	if !found {
-		tagid = data.Db.Tags.AddSimple(tagname)
-	}
-
 	kind, idx := findTableById(id)
 	switch kind {
 	case kindCategory:
-		AddTagById(&data.Db.Categories, idx, tagid, tagname)
+		AddTagById(&data.Db.Categories, idx, tagname)
 	case kindWarehouse:
-		AddTagById(&data.Db.Warehouses, idx, tagid, tagname)
+		AddTagById(&data.Db.Warehouses, idx, tagname)
 	case kindRoom:
-		AddTagById(&data.Db.Rooms, idx, tagid, tagname)
+		AddTagById(&data.Db.Rooms, idx, tagname)
 	case kindShelf:
-		AddTagById(&data.Db.Shelves, idx, tagid, tagname)
+		AddTagById(&data.Db.Shelves, idx, tagname)
 	case kindBox:
-		AddTagById(&data.Db.Boxes, idx, tagid, tagname)
+		AddTagById(&data.Db.Boxes, idx, tagname)
 	case kindItem:
-		AddTagById(&data.Db.Items, idx, tagid, tagname)
+		AddTagById(&data.Db.Items, idx, tagname)
 	default:
 		fmt.Println("No record found.")
 	}
@@ -497,22 +835,22 @@ func ShowAny(id uint32) {
 	}
 }
 
-// DeleteAny deletes any object by id after confirmation.
-func DeleteAny(id uint32) {
+// DeleteAny deletes any object by id, see DeleteSetId.
+func DeleteAny(id uint32, opts DeleteOptions) {
 	kind, idx := findTableById(id)
 	switch kind {
 	case kindCategory:
-		DeleteSetId(&data.Db.Categories, idx, tableName(kind))
+		DeleteSetId(&data.Db.Categories, idx, tableName(kind), opts)
 	case kindWarehouse:
-		DeleteSetId(&data.Db.Warehouses, idx, tableName(kind))
+		DeleteSetId(&data.Db.Warehouses, idx, tableName(kind), opts)
 	case kindRoom:
-		DeleteSetId(&data.Db.Rooms, idx, tableName(kind))
+		DeleteSetId(&data.Db.Rooms, idx, tableName(kind), opts)
 	case kindShelf:
-		DeleteSetId(&data.Db.Shelves, idx, tableName(kind))
+		DeleteSetId(&data.Db.Shelves, idx, tableName(kind), opts)
 	case kindBox:
-		DeleteSetId(&data.Db.Boxes, idx, tableName(kind))
+		DeleteSetId(&data.Db.Boxes, idx, tableName(kind), opts)
 	case kindItem:
-		DeleteSetId(&data.Db.Items, idx, tableName(kind))
+		DeleteSetId(&data.Db.Items, idx, tableName(kind), opts)
 	default:
 		fmt.Println("No record found.")
 	}
@@ -525,38 +863,32 @@ func EditAny(id uint32) {
 	case kindCategory:
 		set, saved := ui.EditItem(data.Db.Categories[idx], GetDropDownOpts(data.Db.Categories[idx].ID), " Edit ")
 		if saved {
-			data.Db.Categories[idx] = set
-			data.Db.Save()
+			editSet(&data.Db.Categories, set)
 		}
 	case kindWarehouse:
 		set, saved := ui.EditItem(data.Db.Warehouses[idx], GetDropDownOpts(data.Db.Warehouses[idx].ID), " Edit ")
 		if saved {
-			data.Db.Warehouses[idx] = set
-			data.Db.Save()
+			editSet(&data.Db.Warehouses, set)
 		}
 	case kindRoom:
 		set, saved := ui.EditItem(data.Db.Rooms[idx], GetDropDownOpts(data.Db.Rooms[idx].ID), " Edit ")
 		if saved {
-			data.Db.Rooms[idx] = set
-			data.Db.Save()
+			editSet(&data.Db.Rooms, set)
 		}
 	case kindShelf:
 		set, saved := ui.EditItem(data.Db.Shelves[idx], GetDropDownOpts(data.Db.Shelves[idx].ID), " Edit ")
 		if saved {
-			data.Db.Shelves[idx] = set
-			data.Db.Save()
+			editSet(&data.Db.Shelves, set)
 		}
 	case kindBox:
 		set, saved := ui.EditItem(data.Db.Boxes[idx], GetDropDownOpts(data.Db.Boxes[idx].ID), " Edit ")
 		if saved {
-			data.Db.Boxes[idx] = set
-			data.Db.Save()
+			editSet(&data.Db.Boxes, set)
 		}
 	case kindItem:
 		set, saved := ui.EditItem(data.Db.Items[idx], GetDropDownOpts(data.Db.Items[idx].ID), " Edit ")
 		if saved {
-			data.Db.Items[idx] = set
-			data.Db.Save()
+			editSet(&data.Db.Items, set)
 		}
 	default:
 		fmt.Println("No record found.")
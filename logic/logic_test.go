@@ -3,6 +3,7 @@ package logic
 import (
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -19,6 +20,8 @@ func TestMain(m *testing.M) {
 	}
 	originalHome := os.Getenv("HOME")
 	_ = os.Setenv("HOME", tempDir)
+	_ = os.Unsetenv("XDG_CONFIG_HOME")
+	_ = os.Unsetenv("LGRT_DB")
 	code := m.Run()
 	if originalHome == "" {
 		_ = os.Unsetenv("HOME")
@@ -29,10 +32,15 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
-// resetDb resets the global database and id source.
+// resetDb resets the global database and id source and removes the database files.
 func resetDb() {
 	id.IdSource.SetLastId(0)
 	data.Db = data.NewDatabase()
+	if path, err := data.Path(); err == nil {
+		_ = os.Remove(path)
+		_ = os.Remove(path + ".journal")
+		_ = os.Remove(path + ".index")
+	}
 }
 
 // captureOutput captures stdout for the duration of fn.
@@ -101,6 +109,25 @@ func TestAddWarehouseDuplicate(t *testing.T) {
 	}
 }
 
+// TestAddWarehouseSaveError verifies a failed save is reported instead of success.
+func TestAddWarehouseSaveError(t *testing.T) {
+	resetDb()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".lgrt"), []byte("not a directory"), 0644); err != nil {
+		t.Fatalf("write blocker: %v", err)
+	}
+	out := captureOutput(t, func() {
+		AddWarehouse("WH1")
+	})
+	if !strings.Contains(out, "could not save") {
+		t.Fatalf("expected save error, got: %s", out)
+	}
+	if strings.Contains(out, "Added") {
+		t.Fatalf("did not expect success message, got: %s", out)
+	}
+}
+
 // TestAddCategoryPositive verifies categories are created.
 func TestAddCategoryPositive(t *testing.T) {
 	resetDb()
@@ -316,7 +343,7 @@ func TestShowSet(t *testing.T) {
 func TestDeleteEditSetInvalid(t *testing.T) {
 	resetDb()
 	out := captureOutput(t, func() {
-		DeleteSet(&data.Db.Categories, "missing", "Category")
+		DeleteSet(&data.Db.Categories, "missing", "Category", DeleteOptions{})
 	})
 	if !strings.Contains(out, "No category with name") {
 		t.Fatalf("expected missing name message, got: %s", out)
@@ -358,20 +385,20 @@ func TestAddRemoveTag(t *testing.T) {
 		t.Fatalf("expected missing tag message, got: %s", out)
 	}
 
-	// add tag to unknown id still creates tag, but reports no record found
+	// add tag to unknown id reports no record found and creates no tag
 	out = captureOutput(t, func() {
 		AddTag("orphan", 999)
 	})
 	if !strings.Contains(out, "No record found") {
 		t.Fatalf("expected no record found, got: %s", out)
 	}
-	if _, ok := data.Db.Tags.GetFirstOccurance("orphan"); !ok {
-		t.Fatalf("expected orphan tag to be created")
+	if _, ok := data.Db.Tags.GetFirstOccurance("orphan"); ok {
+		t.Fatalf("expected no orphan tag")
 	}
 
 	// remove tag from unknown id
 	out = captureOutput(t, func() {
-		RemoveTag("orphan", 999)
+		RemoveTag("fragile", 999)
 	})
 	if !strings.Contains(out, "No record found") {
 		t.Fatalf("expected no record found on remove, got: %s", out)
@@ -446,9 +473,109 @@ func TestShowAnyUnknown(t *testing.T) {
 func TestDeleteAnyUnknown(t *testing.T) {
 	resetDb()
 	out := captureOutput(t, func() {
-		DeleteAny(12345)
+		DeleteAny(12345, DeleteOptions{})
 	})
 	if !strings.Contains(out, "No record found") {
 		t.Fatalf("expected no record found message, got: %s", out)
 	}
 }
+
+// TestRepair verifies that a truncated database is salvaged and saved.
+func TestRepair(t *testing.T) {
+	resetDb()
+	home, _ := os.UserHomeDir()
+	dbPath := filepath.Join(home, ".lgrt", "lgrtdata.json")
+	_ = os.MkdirAll(filepath.Dir(dbPath), os.ModePerm)
+	if err := os.WriteFile(dbPath, []byte(`{"warehouses":[{"id":1,"name":"Home"}],"rooms":[{"id":2,"na`), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	out := captureOutput(t, func() {
+		Repair()
+	})
+	if !strings.Contains(out, "Repaired database saved") {
+		t.Fatalf("expected repair message, got: %s", out)
+	}
+	if !strings.Contains(out, "items") || !strings.Contains(out, "lost completely") {
+		t.Fatalf("expected dropped tables to be reported, got: %s", out)
+	}
+
+	data.Db = data.NewDatabase()
+	if err := data.Db.Load(); err != nil {
+		t.Fatalf("repaired database does not load: %v", err)
+	}
+	if len(data.Db.Warehouses) != 1 {
+		t.Fatalf("expected salvaged warehouse, got %+v", data.Db.Warehouses)
+	}
+
+	out = captureOutput(t, func() {
+		Repair()
+	})
+	if !strings.Contains(out, "nothing to repair") {
+		t.Fatalf("expected clean message, got: %s", out)
+	}
+}
+
+// TestMigrate verifies copying the database to the SQLite backend.
+func TestMigrate(t *testing.T) {
+	resetDb()
+	AddWarehouse("WH1")
+	target := filepath.Join(t.TempDir(), "inventory.sqlite")
+
+	out := captureOutput(t, func() {
+		Migrate("sqlite", target)
+	})
+	if !strings.Contains(out, "Migrated database") {
+		t.Fatalf("expected migration message, got: %s", out)
+	}
+	db2 := data.NewDatabase()
+	if err := db2.LoadFile(target); err != nil || len(db2.Warehouses) != 1 {
+		t.Fatalf("migrated database not readable: %v %+v", err, db2.Warehouses)
+	}
+
+	out = captureOutput(t, func() {
+		Migrate("sqlite", target)
+	})
+	if !strings.Contains(out, "already exists") {
+		t.Fatalf("expected refusal to overwrite, got: %s", out)
+	}
+	out = captureOutput(t, func() {
+		Migrate("sqlite", filepath.Join(t.TempDir(), "inventory.json"))
+	})
+	if !strings.Contains(out, "does not match") {
+		t.Fatalf("expected extension mismatch, got: %s", out)
+	}
+}
+
+// TestSaveConflict verifies that a concurrent save is detected and nothing is written.
+func TestSaveConflict(t *testing.T) {
+	resetDb()
+	AddWarehouse("WH1")
+
+	other := data.NewDatabase()
+	if err := other.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	other.Warehouses.Add(data.NewDataset[data.Warehouse]("Other", data.Warehouse{}))
+	if err := other.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	out := captureOutput(t, func() {
+		AddWarehouse("WH2")
+	})
+	if !strings.Contains(out, "changed by another lgrt process") {
+		t.Fatalf("expected conflict message, got: %s", out)
+	}
+	if strings.Contains(out, "Added") {
+		t.Fatalf("did not expect success message, got: %s", out)
+	}
+
+	check := data.NewDatabase()
+	if err := check.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(check.Warehouses) != 2 || check.Warehouses[1].Name != "Other" {
+		t.Fatalf("concurrent change was overwritten: %+v", check.Warehouses)
+	}
+}
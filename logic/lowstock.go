@@ -0,0 +1,63 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/elsni/lagerator/data"
+	"github.com/elsni/lagerator/terminal"
+)
+
+// LowStock prints the items at or below their minimum amount grouped by
+// category and returns how many there are.
+func LowStock() int {
+	var low []data.Dataset[data.Item]
+	for _, set := range data.Db.Items {
+		if !set.Deleted && set.Data.IsLow() && data.InScope(set) {
+			low = append(low, set)
+		}
+	}
+	category := func(set data.Dataset[data.Item]) string {
+		if cat, ok := data.Db.Categories.GetPtr(set.Data.CategoryId); ok {
+			return cat.Name
+		}
+		return ""
+	}
+	// by category, items without category last, then by name
+	sort.SliceStable(low, func(i, j int) bool {
+		a, b := category(low[i]), category(low[j])
+		if (a == "") != (b == "") {
+			return b == ""
+		}
+		if !strings.EqualFold(a, b) {
+			return strings.ToUpper(a) < strings.ToUpper(b)
+		}
+		return strings.ToUpper(low[i].Name) < strings.ToUpper(low[j].Name)
+	})
+	if data.Output != data.OutputText {
+		data.WriteSets(low)
+		return len(low)
+	}
+	if len(low) == 0 {
+		fmt.Println("No items are low on stock")
+		return 0
+	}
+	group := "-"
+	for _, set := range low {
+		if c := category(set); c != group {
+			if group != "-" {
+				fmt.Println()
+			}
+			group = c
+			if c == "" {
+				c = "No category"
+			}
+			fmt.Println(terminal.GetHeadlineText(fmt.Sprintf(" %s ", c)))
+			fmt.Println(terminal.GetHeadlineText(fmt.Sprintf("%5s %-30s %6s %6s %-15s", "ID", "Name", "Amount", "Min", "Box")))
+		}
+		fmt.Printf("%5d %-30s %6d %6d %-15s\n", set.ID, set.GetPrintName(30), set.Data.Amount, set.Data.MinAmount, data.GetPrintNameById(&data.Db.Boxes, set.Data.BoxId, 15))
+	}
+	fmt.Printf("\n%d items are low on stock\n", len(low))
+	return len(low)
+}
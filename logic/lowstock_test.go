@@ -0,0 +1,32 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elsni/lagerator/data"
+)
+
+// TestLowStock verifies which items are reported and their grouping by category.
+func TestLowStock(t *testing.T) {
+	resetDb()
+	screws := data.Db.Categories.AddSimple("Screws")
+	data.Db.Items.Add(data.NewDataset("M4", data.Item{Amount: 10, MinAmount: 20, CategoryId: screws}))
+	data.Db.Items.Add(data.NewDataset("M3", data.Item{Amount: 20, MinAmount: 20, CategoryId: screws}))
+	data.Db.Items.Add(data.NewDataset("M5", data.Item{Amount: 21, MinAmount: 20, CategoryId: screws}))
+	data.Db.Items.Add(data.NewDataset("AA", data.Item{Amount: 0, MinAmount: 4}))
+	data.Db.Items.Add(data.NewDataset("Drill", data.Item{Amount: 0}))
+
+	var count int
+	out := captureOutput(t, func() { count = LowStock() })
+	if count != 3 {
+		t.Fatalf("expected 3 low items, got %d: %s", count, out)
+	}
+	if strings.Contains(out, "M5") || strings.Contains(out, "Drill") {
+		t.Fatalf("unexpected items in report: %s", out)
+	}
+	if !(strings.Index(out, "Screws") < strings.Index(out, "M3") && strings.Index(out, "M3") < strings.Index(out, "M4") &&
+		strings.Index(out, "M4") < strings.Index(out, "No category") && strings.Index(out, "No category") < strings.Index(out, "AA")) {
+		t.Fatalf("unexpected grouping: %s", out)
+	}
+}
@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elsni/lagerator/config"
+	"github.com/elsni/lagerator/data"
+)
+
+// Migrate copies the database into a new file using another storage backend.
+// Without a target the current file name is used with the backend's extension.
+func Migrate(format string, target string) {
+	var ext string
+	switch strings.ToLower(format) {
+	case "sqlite":
+		ext = ".sqlite"
+	case "json":
+		ext = ".json"
+	default:
+		fmt.Printf("Unknown storage format \"%s\", use sqlite or json\n", format)
+		return
+	}
+	source, err := data.Path()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if target == "" {
+		target = strings.TrimSuffix(source, filepath.Ext(source)) + ext
+	}
+	target, err = config.ExpandPath(target)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if data.IsSQLitePath(target) != (ext == ".sqlite") {
+		fmt.Printf("The file name \"%s\" does not match the %s format\n", target, format)
+		return
+	}
+	if _, err := os.Stat(target); err == nil {
+		fmt.Printf("The file \"%s\" already exists\n", target)
+		return
+	}
+	if err := data.Db.SaveTo(target); err != nil {
+		fmt.Printf("Error: could not write %s: %v\n", target, err)
+		return
+	}
+	fmt.Printf("Migrated database to %s\n", target)
+	fmt.Printf("Use it with \"lgrt --db %s\" or register it with \"lgrt profile add\"\n", target)
+}
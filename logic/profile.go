@@ -0,0 +1,83 @@
+package logic
+
+import (
+	"fmt"
+
+	"github.com/elsni/lagerator/config"
+	"github.com/elsni/lagerator/data"
+	"github.com/elsni/lagerator/terminal"
+)
+
+// ListProfiles prints all registered inventories and the database in use.
+func ListProfiles() {
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		fmt.Printf("Error: could not read profiles: %v\n", err)
+		return
+	}
+	fmt.Println(terminal.GetHeadlineText(fmt.Sprintf(" %-20s %-50s", "Profile", "Database")))
+	for _, p := range profiles.List() {
+		marker := " "
+		if p.Active {
+			marker = "*"
+		}
+		fmt.Printf("%s%-20s %s\n", marker, p.Name, p.Path)
+	}
+	if path, err := data.Path(); err == nil {
+		fmt.Printf("\nDatabase in use: %s\n", path)
+	}
+}
+
+// AddProfile registers a named database file.
+func AddProfile(name string, path string) {
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		fmt.Printf("Error: could not read profiles: %v\n", err)
+		return
+	}
+	if err := profiles.Add(name, path); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if err := profiles.Save(); err != nil {
+		fmt.Printf("Error: could not save profiles: %v\n", err)
+		return
+	}
+	fmt.Printf("Added profile \"%s\" (%s)\n", name, profiles.Profiles[name])
+}
+
+// UseProfile switches the active inventory.
+func UseProfile(name string) {
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		fmt.Printf("Error: could not read profiles: %v\n", err)
+		return
+	}
+	if err := profiles.Use(name); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if err := profiles.Save(); err != nil {
+		fmt.Printf("Error: could not save profiles: %v\n", err)
+		return
+	}
+	fmt.Printf("Profile \"%s\" is now active\n", name)
+}
+
+// RemoveProfile unregisters a named database file without deleting it.
+func RemoveProfile(name string) {
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		fmt.Printf("Error: could not read profiles: %v\n", err)
+		return
+	}
+	if err := profiles.Remove(name); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if err := profiles.Save(); err != nil {
+		fmt.Printf("Error: could not save profiles: %v\n", err)
+		return
+	}
+	fmt.Printf("Removed profile \"%s\", the database file was kept\n", name)
+}
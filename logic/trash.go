@@ -0,0 +1,99 @@
+package logic
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elsni/lagerator/data"
+	"github.com/elsni/lagerator/terminal"
+)
+
+// PrintTrash lists all deleted records with their deletion time.
+func PrintTrash() {
+	list := data.Db.Trash()
+	if len(list) == 0 {
+		fmt.Println("The trash is empty")
+		return
+	}
+	fmt.Println(terminal.GetHeadlineText(fmt.Sprintf("%5s %-10s %-30s %-16s", "ID", "Type", "Name", "Deleted")))
+	for _, entry := range list {
+		fmt.Printf("%5d %-10s %-30s %s\n", entry.ID, entry.Type, entry.Name, terminal.GetTimeString(entry.DeletedAt))
+	}
+}
+
+// Restore undeletes a record and every deleted record it depends on.
+func Restore(id uint32) {
+	record, found := data.Db.FindRecord(id)
+	if !found {
+		fmt.Println("No record found.")
+		return
+	}
+	if !record.Deleted {
+		fmt.Printf("%s \"%s\" is not deleted\n", record.Type, record.Name)
+		return
+	}
+	t := begin("restore")
+	var restored []data.RecordInfo
+	restoreChain(t, record, &restored)
+	if !t.commit(fmt.Sprintf("restore %s \"%s\"", strings.ToLower(record.Type), record.Name)) {
+		return
+	}
+	for _, r := range restored {
+		fmt.Printf("Restored %s \"%s\"\n", strings.ToLower(r.Type), r.Name)
+	}
+}
+
+// restoreChain restores a deleted record and walks up to its deleted parents.
+func restoreChain(t *tx, record data.RecordInfo, restored *[]data.RecordInfo) {
+	t.touchRef(record.Ref)
+	data.Db.Restore(record.Ref)
+	*restored = append(*restored, record)
+	for _, ref := range data.Db.Parents(record.Ref) {
+		parent, found := data.Db.Record(ref)
+		if !found {
+			fmt.Printf("Warning: %s \"%s\" refers to %s %d, which was purged\n", strings.ToLower(record.Type), record.Name, ref.Table, ref.ID)
+			continue
+		}
+		if parent.Deleted {
+			restoreChain(t, parent, restored)
+		}
+	}
+}
+
+// Purge physically removes records deleted more than olderThan ago, all
+// deleted records if olderThan is 0. Records still referenced by a live
+// record are kept, purged tags are removed from every record carrying them.
+func Purge(olderThan time.Duration) {
+	cutoff := time.Now().Add(-olderThan).Unix()
+	t := begin("purge")
+	purged := 0
+	for _, entry := range data.Db.Trash() {
+		if entry.DeletedAt > cutoff {
+			continue
+		}
+		if entry.Table == "tags" {
+			for _, ref := range data.Db.Tagged(entry.ID) {
+				t.touchRef(ref)
+				data.Db.Untag(ref, entry.ID)
+			}
+		} else if children := data.Db.LiveChildren(entry.Ref); len(children) > 0 {
+			fmt.Printf("Kept %s \"%s\", it is still used by %d live records\n", strings.ToLower(entry.Type), entry.Name, len(children))
+			continue
+		}
+		t.touchRef(entry.Ref)
+		if err := data.Db.RemoveRecord(entry.Table, entry.ID); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		purged++
+	}
+	if purged == 0 {
+		fmt.Println("Nothing to purge")
+		return
+	}
+	if !t.commit(fmt.Sprintf("purge %d deleted records", purged)) {
+		return
+	}
+	fmt.Printf("Purged %d deleted records\n", purged)
+}
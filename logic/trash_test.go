@@ -0,0 +1,202 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elsni/lagerator/data"
+)
+
+// TestRestoreParentChain verifies that restoring an item restores its deleted box and shelf.
+func TestRestoreParentChain(t *testing.T) {
+	resetDb()
+	AddWarehouse("WH1")
+	SwitchWarehouse("WH1")
+	AddRoomToCurrentWarehouse("R1")
+	AddShelfToRoom("S1", "R1")
+	AddBoxToShelf("B1", "S1")
+	shelf := data.Db.Shelves[0]
+	box := data.Db.Boxes[0]
+	item := data.NewDataset[data.Item]("Drill", data.Item{BoxId: box.ID})
+	data.Db.Items.Add(item)
+	data.Db.Items.Delete(item.ID)
+	data.Db.Boxes.Delete(box.ID)
+	data.Db.Shelves.Delete(shelf.ID)
+
+	out := captureOutput(t, PrintTrash)
+	if !strings.Contains(out, "Drill") || !strings.Contains(out, "B1") {
+		t.Fatalf("expected deleted records in trash, got: %s", out)
+	}
+
+	out = captureOutput(t, func() { Restore(item.ID) })
+	if !strings.Contains(out, "Restored item \"Drill\"") || !strings.Contains(out, "Restored shelf \"S1\"") {
+		t.Fatalf("expected restore messages, got: %s", out)
+	}
+	for _, id := range []uint32{item.ID, box.ID, shelf.ID} {
+		if record, _ := data.Db.FindRecord(id); record.Deleted {
+			t.Fatalf("expected %s %d to be restored", record.Type, id)
+		}
+	}
+
+	out = captureOutput(t, func() { Restore(item.ID) })
+	if !strings.Contains(out, "is not deleted") {
+		t.Fatalf("expected not deleted message, got: %s", out)
+	}
+}
+
+// TestPurge verifies that purge keeps records with live children and strips purged tags.
+func TestPurge(t *testing.T) {
+	resetDb()
+	AddWarehouse("WH1")
+	SwitchWarehouse("WH1")
+	AddRoomToCurrentWarehouse("R1")
+	AddShelfToRoom("S1", "R1")
+	AddBoxToShelf("B1", "S1")
+	AddBoxToShelf("B2", "S1")
+	box1 := data.Db.Boxes[0]
+	box2 := data.Db.Boxes[1]
+	item := data.NewDataset[data.Item]("Drill", data.Item{BoxId: box1.ID})
+	data.Db.Items.Add(item)
+	AddTag("old", item.ID)
+	tag := data.Db.Tags[0]
+	data.Db.Boxes.Delete(box1.ID)
+	data.Db.Boxes.Delete(box2.ID)
+	data.Db.Tags.Delete(tag.ID)
+
+	out := captureOutput(t, func() { Purge(0) })
+	if !strings.Contains(out, "Kept box \"B1\"") || !strings.Contains(out, "Purged 2 deleted records") {
+		t.Fatalf("unexpected purge output: %s", out)
+	}
+	if data.Db.Boxes.GetIdxAny(box2.ID) > -1 || data.Db.Tags.GetIdxAny(tag.ID) > -1 {
+		t.Fatal("expected box and tag to be purged")
+	}
+	if data.Db.Boxes.GetIdxAny(box1.ID) < 0 {
+		t.Fatal("expected box with live item to be kept")
+	}
+	if len(data.Db.Items[0].Tags) != 0 {
+		t.Fatalf("expected purged tag to be stripped, got %v", data.Db.Items[0].Tags)
+	}
+
+	captureOutput(t, Undo)
+	if data.Db.Boxes.GetIdxAny(box2.ID) < 0 || len(data.Db.Items[0].Tags) != 1 {
+		t.Fatal("expected undo to bring back the purged records")
+	}
+}
+
+// TestDeleteCascade verifies that --cascade deletes the whole subtree.
+func TestDeleteCascade(t *testing.T) {
+	resetDb()
+	AddWarehouse("WH1")
+	SwitchWarehouse("WH1")
+	AddRoomToCurrentWarehouse("R1")
+	AddShelfToRoom("S1", "R1")
+	AddBoxToShelf("B1", "S1")
+	item := data.NewDataset[data.Item]("Drill", data.Item{BoxId: data.Db.Boxes[0].ID})
+	data.Db.Items.Add(item)
+
+	out := captureOutput(t, func() { DeleteSet(&data.Db.Rooms, "R1", "Room", DeleteOptions{Cascade: true}) })
+	if !strings.Contains(out, "1 shelves, 1 boxes, 1 items") {
+		t.Fatalf("expected descendant counts, got: %s", out)
+	}
+	if len(data.Db.Trash()) != 4 {
+		t.Fatalf("expected room, shelf, box and item in trash, got %+v", data.Db.Trash())
+	}
+
+	captureOutput(t, Undo)
+	if len(data.Db.Trash()) != 0 {
+		t.Fatalf("expected undo to restore the subtree, got %+v", data.Db.Trash())
+	}
+}
+
+// TestDeleteCategoryKeepsItems verifies that deleting a category keeps its
+// items without category, also with --cascade.
+func TestDeleteCategoryKeepsItems(t *testing.T) {
+	resetDb()
+	AddWarehouse("WH1")
+	AddCategory("Tools")
+	cat := data.Db.Categories[0]
+	data.Db.Items.Add(data.NewDataset[data.Item]("Drill", data.Item{CategoryId: cat.ID}))
+
+	out := captureOutput(t, func() { DeleteAny(cat.ID, DeleteOptions{Cascade: true}) })
+	if !strings.Contains(out, "Its 1 items have no category now") {
+		t.Fatalf("expected to be told about the kept items, got: %s", out)
+	}
+	if data.Db.Items[0].Deleted || data.Db.Items[0].Data.CategoryId != 0 {
+		t.Fatalf("expected item to stay without category, got %+v", data.Db.Items[0])
+	}
+	if len(data.Db.Trash()) != 1 {
+		t.Fatalf("expected only the category in trash, got %+v", data.Db.Trash())
+	}
+
+	captureOutput(t, Undo)
+	if data.Db.Items[0].Data.CategoryId != cat.ID {
+		t.Fatalf("expected undo to restore the category of the item")
+	}
+}
+
+// TestDeleteReparent verifies that --reparent moves the children to the new parent.
+func TestDeleteReparent(t *testing.T) {
+	resetDb()
+	AddWarehouse("WH1")
+	SwitchWarehouse("WH1")
+	AddRoomToCurrentWarehouse("R1")
+	AddShelfToRoom("S1", "R1")
+	AddBoxToShelf("B1", "S1")
+	AddBoxToShelf("B2", "S1")
+	box1 := data.Db.Boxes[0]
+	box2 := data.Db.Boxes[1]
+	data.Db.Items.Add(data.NewDataset[data.Item]("Drill", data.Item{BoxId: box1.ID}))
+	data.Db.Items.Add(data.NewDataset[data.Item]("Saw", data.Item{BoxId: box1.ID}))
+
+	out := captureOutput(t, func() { DeleteAny(box1.ID, DeleteOptions{Reparent: "B1"}) })
+	if !strings.Contains(out, "to itself") {
+		t.Fatalf("expected refusal to move into itself, got: %s", out)
+	}
+	out = captureOutput(t, func() { DeleteAny(box1.ID, DeleteOptions{Reparent: "B9"}) })
+	if !strings.Contains(out, "No box with name \"B9\" found") || !strings.Contains(out, "Nothing deleted") {
+		t.Fatalf("expected unknown target to be refused, got: %s", out)
+	}
+	if data.Db.Boxes.GetIdx(box1.ID) < 0 {
+		t.Fatal("expected box to be kept")
+	}
+	out = captureOutput(t, func() { DeleteAny(box1.ID, DeleteOptions{Reparent: "B2"}) })
+	if !strings.Contains(out, "moving 2 items to \"B2\"") {
+		t.Fatalf("expected reparent message, got: %s", out)
+	}
+	for _, item := range data.Db.Items {
+		if item.Deleted || item.Data.BoxId != box2.ID {
+			t.Fatalf("expected live item in box %d, got %+v", box2.ID, item)
+		}
+	}
+	if data.Db.Boxes.GetIdx(box1.ID) > -1 {
+		t.Fatal("expected box to be deleted")
+	}
+}
+
+// TestFsckFix verifies that fsck moves orphans to Lost & Found and strips dangling tags.
+func TestFsckFix(t *testing.T) {
+	resetDb()
+	item := data.NewDataset[data.Item]("Drill", data.Item{BoxId: 999, CategoryId: 998})
+	item.Tags = []uint32{997}
+	data.Db.Items.Add(item)
+
+	out := captureOutput(t, func() { Fsck(FsckOptions{}) })
+	if !strings.Contains(out, "3 problems found") {
+		t.Fatalf("expected problem report, got: %s", out)
+	}
+	out = captureOutput(t, func() { Fsck(FsckOptions{Orphans: true, Tags: true}) })
+	if !strings.Contains(out, "moved 1 orphans") {
+		t.Fatalf("expected fix summary, got: %s", out)
+	}
+	boxid, found := data.Db.Boxes.GetFirstOccurance("Lost & Found")
+	if !found || data.Db.Items[0].Data.BoxId != boxid {
+		t.Fatalf("expected item in Lost & Found box, got %+v", data.Db.Items[0])
+	}
+	if data.Db.Items[0].Data.CategoryId != 0 || len(data.Db.Items[0].Tags) != 0 {
+		t.Fatalf("expected dangling references to be cleared, got %+v", data.Db.Items[0])
+	}
+	out = captureOutput(t, func() { Fsck(FsckOptions{}) })
+	if !strings.Contains(out, "No problems found") {
+		t.Fatalf("expected clean database, got: %s", out)
+	}
+}
@@ -0,0 +1,83 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/elsni/lagerator/data"
+	"github.com/elsni/lagerator/terminal"
+)
+
+// valueGroup sums the value of the items in a container or category.
+type valueGroup struct {
+	name   string
+	items  int
+	totals data.Totals
+}
+
+// Value prints the value of all items, Amount times unit price, per
+// warehouse, room, box and category.
+func Value() {
+	groups := map[data.Ref]*valueGroup{}
+	add := func(ref data.Ref, name string, set data.Dataset[data.Item]) {
+		g, ok := groups[ref]
+		if !ok {
+			g = &valueGroup{name: name, totals: data.Totals{}}
+			groups[ref] = g
+		}
+		g.items++
+		g.totals.Add(set.Data.Value(), set.Data.Currency)
+	}
+	total := data.Totals{}
+	priced, unpriced := 0, 0
+	for _, set := range data.Db.Items {
+		if set.Deleted || !data.InScope(set) {
+			continue
+		}
+		if set.Data.UnitPrice == 0 {
+			unpriced++
+			continue
+		}
+		priced++
+		total.Add(set.Data.Value(), set.Data.Currency)
+		path := data.ItemPath(set.Data)
+		for _, ref := range []data.Ref{path[3], path[2], path[0]} {
+			add(ref, refName(ref), set)
+		}
+		category := data.Ref{Table: "categories", ID: set.Data.CategoryId}
+		add(category, refName(category), set)
+	}
+	if priced == 0 {
+		fmt.Println("No items with a price")
+		return
+	}
+	for _, section := range []struct{ title, table string }{
+		{"Warehouse", "warehouses"}, {"Room", "rooms"}, {"Box", "boxes"}, {"Category", "categories"},
+	} {
+		var list []*valueGroup
+		for ref, g := range groups {
+			if ref.Table == section.table {
+				list = append(list, g)
+			}
+		}
+		sort.Slice(list, func(i, j int) bool { return strings.ToUpper(list[i].name) < strings.ToUpper(list[j].name) })
+		fmt.Println(terminal.GetHeadlineText(fmt.Sprintf("%-30s %6s %-30s", section.title, "Items", "Value")))
+		for _, g := range list {
+			fmt.Printf("%-30s %6d %s\n", g.name, g.items, g.totals)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%s %s\n", terminal.GetLabelText("Total"), total)
+	if unpriced > 0 {
+		fmt.Printf("%d items have no price\n", unpriced)
+	}
+}
+
+// refName returns the name of a live record, or "None" for a missing one.
+func refName(ref data.Ref) string {
+	if info, ok := data.Db.Record(ref); ok && !info.Deleted {
+		return info.Name
+	}
+	return "None"
+}
@@ -0,0 +1,38 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elsni/lagerator/data"
+)
+
+// TestValue verifies the valuation report and the value shown for containers.
+func TestValue(t *testing.T) {
+	resetDb()
+	AddWarehouse("Home")
+	SwitchWarehouse("Home")
+	csv := writeCSV(t, "name,box,amount,price,currency,category\n"+
+		"Drill,Basement/Rack/Tools,2,49.50,EUR,Power tools\n"+
+		"Saw,Basement/Rack/Tools,1,20,EUR,\n"+
+		"Lamp,Attic/Beam/Misc,1,15,USD,\n"+
+		"Rope,Attic/Beam/Misc,1,,,\n")
+	out := captureOutput(t, func() { ImportCSV(csv, CSVImportOptions{Create: true}) })
+	if !strings.Contains(out, "Imported 4 items") {
+		t.Fatalf("unexpected import: %s", out)
+	}
+
+	out = captureOutput(t, Value)
+	for _, want := range []string{"Basement                            2 119.00 EUR", "Power tools                         1 99.00 EUR",
+		"None                                2 20.00 EUR + 15.00 USD", "119.00 EUR + 15.00 USD", "1 items have no price"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in report: %s", want, out)
+		}
+	}
+
+	room, _ := data.Db.Rooms.GetFirstOccurance("Attic")
+	out = captureOutput(t, func() { ShowAny(room) })
+	if !strings.Contains(out, "15.00 USD (1 items with price)") {
+		t.Fatalf("expected room value, got: %s", out)
+	}
+}
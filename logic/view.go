@@ -0,0 +1,95 @@
+package logic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elsni/lagerator/data"
+	"github.com/elsni/lagerator/terminal"
+)
+
+// SaveView stores a search query under a name, replacing a view of the same name.
+func SaveView(name string, query string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		fmt.Println("Error: a view needs a name")
+		return
+	}
+	if _, err := data.ParseQuery(query); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	_, exists := data.Db.Views[name]
+	t := begin("save")
+	t.touchValue("views")
+	if data.Db.Views == nil {
+		data.Db.Views = map[string]string{}
+	}
+	data.Db.Views[name] = query
+	if !t.commit(fmt.Sprintf("save view \"%s\"", name)) {
+		return
+	}
+	if exists {
+		fmt.Printf("Updated view \"%s\"\n", name)
+	} else {
+		fmt.Printf("Saved view \"%s\"\n", name)
+	}
+}
+
+// RunView lists the items of a saved view.
+func RunView(name string, sortname bool) {
+	query, ok := data.Db.Views[name]
+	if !ok {
+		fmt.Printf("Unknown view \"%s\"\n", name)
+		return
+	}
+	data.Db.FindItem(query, sortname)
+}
+
+// ListViews prints the saved views with their queries and number of items.
+func ListViews() {
+	names := data.Db.ViewNames()
+	if len(names) == 0 {
+		fmt.Println("No saved views")
+		return
+	}
+	fmt.Println(terminal.GetHeadlineText(fmt.Sprintf("%-20s %5s %-50s", "View", "Items", "Query")))
+	for _, name := range names {
+		count := "?"
+		if filter, err := data.Db.ViewFilter(name); err == nil {
+			n := 0
+			for _, set := range data.Db.Items {
+				if !set.Deleted && filter(set) {
+					n++
+				}
+			}
+			count = fmt.Sprint(n)
+		}
+		fmt.Printf("%-20s %5s %s\n", name, count, data.Db.Views[name])
+	}
+}
+
+// DeleteView removes a saved view.
+func DeleteView(name string) {
+	if _, ok := data.Db.Views[name]; !ok {
+		fmt.Printf("Unknown view \"%s\"\n", name)
+		return
+	}
+	t := begin("delete")
+	t.touchValue("views")
+	delete(data.Db.Views, name)
+	if t.commit(fmt.Sprintf("delete view \"%s\"", name)) {
+		fmt.Printf("Deleted view \"%s\"\n", name)
+	}
+}
+
+// UseView limits the item lists of this call to a saved view.
+func UseView(name string) bool {
+	filter, err := data.Db.ViewFilter(name)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return false
+	}
+	data.Scope = filter
+	return true
+}
@@ -0,0 +1,107 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elsni/lagerator/data"
+)
+
+// TestViews verifies saving, running, listing and deleting views and the
+// --view scope of item lists.
+func TestViews(t *testing.T) {
+	resetDb()
+	AddWarehouse("Home")
+	broken := data.NewDataset("Drill", data.Item{})
+	broken.Tags = data.GetTagIds("broken")
+	data.Db.Items.Add(broken)
+	data.Db.Items.Add(data.NewDataset("Saw", data.Item{}))
+
+	out := captureOutput(t, func() { SaveView("repair", "tag:broken (") })
+	if !strings.Contains(out, "Error:") || len(data.Db.Views) != 0 {
+		t.Fatalf("expected an invalid query to be rejected, got: %s", out)
+	}
+	out = captureOutput(t, func() { SaveView("repair", "tag:broken") })
+	if !strings.Contains(out, "Saved view \"repair\"") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+
+	data.Db = data.NewDatabase()
+	if err := data.Db.Load(); err != nil || data.Db.Views["repair"] != "tag:broken" {
+		t.Fatalf("expected the view to be stored, got %v: %v", data.Db.Views, err)
+	}
+	out = captureOutput(t, func() { RunView("repair", false) })
+	if !strings.Contains(out, "Drill") || strings.Contains(out, "Saw") {
+		t.Fatalf("unexpected view items: %s", out)
+	}
+	out = captureOutput(t, ListViews)
+	if !strings.Contains(out, "repair") || !strings.Contains(out, "    1 tag:broken") {
+		t.Fatalf("unexpected view list: %s", out)
+	}
+
+	if !UseView("repair") {
+		t.Fatal("expected the view to be usable as scope")
+	}
+	out = captureOutput(t, func() { data.Db.Items.PrintList(false) })
+	data.Scope = nil
+	if !strings.Contains(out, "Drill") || strings.Contains(out, "Saw") {
+		t.Fatalf("expected the list to be limited to the view: %s", out)
+	}
+
+	out = captureOutput(t, func() { DeleteView("repair") })
+	if !strings.Contains(out, "Deleted view") || len(data.Db.Views) != 0 {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	out = captureOutput(t, func() { RunView("repair", false) })
+	if !strings.Contains(out, "Unknown view") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+
+	out = captureOutput(t, Undo)
+	if !strings.Contains(out, "Undone: delete view \"repair\"") || data.Db.Views["repair"] != "tag:broken" {
+		t.Fatalf("expected undo to bring back the view, got: %s", out)
+	}
+	captureOutput(t, Undo)
+	if len(data.Db.Views) != 0 {
+		t.Fatalf("expected undo to remove the saved view, got %v", data.Db.Views)
+	}
+}
+
+// TestViewScopesReports verifies that the reports are limited to the --view scope.
+func TestViewScopesReports(t *testing.T) {
+	resetDb()
+	AddWarehouse("Home")
+	expires := data.Today().AddDays(3)
+	for name, price := range map[string]data.Money{"Drill": 100, "Saw": 5000} {
+		set := data.NewDataset(name, data.Item{Amount: 1, MinAmount: 2, Expires: expires, UnitPrice: price, Loan: &data.Loan{Borrower: "Anna"}})
+		if name == "Drill" {
+			set.Tags = data.GetTagIds("broken")
+		}
+		data.Db.Items.Add(set)
+	}
+	captureOutput(t, func() { SaveView("repair", "tag:broken") })
+
+	reports := map[string]func(){
+		"lent":     ListLent,
+		"low":      func() { LowStock() },
+		"expiring": func() { Expiring(7) },
+		"fuzzy":    func() { data.Db.FindItemFuzzy("saw", false) },
+	}
+	for name, report := range reports {
+		if out := captureOutput(t, report); !strings.Contains(out, "Saw") {
+			t.Fatalf("expected %s to list Saw without a view: %s", name, out)
+		}
+	}
+	if !UseView("repair") {
+		t.Fatal("expected the view to be usable as scope")
+	}
+	defer func() { data.Scope = nil }()
+	for name, report := range reports {
+		if out := captureOutput(t, report); strings.Contains(out, "Saw") {
+			t.Fatalf("expected %s to be limited to the view: %s", name, out)
+		}
+	}
+	if out := captureOutput(t, Value); strings.Contains(out, "51.00") || !strings.Contains(out, "1.00") {
+		t.Fatalf("expected the value of the view only: %s", out)
+	}
+}
@@ -1,19 +1,16 @@
 package main
 
 import (
-	"fmt"
+	"os"
 
 	"github.com/elsni/lagerator/args"
-	"github.com/elsni/lagerator/data"
 	"github.com/elsni/lagerator/loggi"
 )
 
 // main is the program entry point.
 func main() {
 	//ui.TestForm()
-	fmt.Println()
-	data.Db.Load()
 	args.ProcessArgs()
-	fmt.Println()
 	loggi.Log.Print(false)
+	os.Exit(args.ExitCode)
 }
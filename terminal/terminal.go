@@ -11,6 +11,7 @@ const (
 	COLORWHITE    int = 15
 	COLORDARKGRAY int = 235
 	COLORYELLOW   int = 11
+	COLORRED      int = 9
 )
 
 // MoveToColumn returns an ANSI escape to move the cursor to a column.
@@ -48,6 +49,22 @@ func GetLabelText(text string) string {
 	return fmt.Sprintf("%s%s%-12s%s", SetBgColor(COLORDARKGRAY), SetFgColor(COLORYELLOW), text, ResetColor())
 }
 
+// GetWarningText formats text in red, e.g. for overdue dates.
+func GetWarningText(text string) string {
+	return fmt.Sprintf("%s%s%s", SetFgColor(COLORRED), text, ResetColor())
+}
+
+// GetHighlightText formats text in yellow, e.g. for search matches.
+func GetHighlightText(text string) string {
+	return fmt.Sprintf("%s%s%s", SetFgColor(COLORYELLOW), text, ResetColor())
+}
+
+// GetDateString formats a Unix timestamp as a date without time.
+func GetDateString(ts int64) string {
+	t := time.Unix(ts, 0)
+	return t.Format("02.01.2006")
+}
+
 // GetTimeString formats a Unix timestamp as a date string.
 func GetTimeString(ts int64) string {
 	t := time.Unix(ts, 0)
@@ -0,0 +1,471 @@
+// Package tui is the full-screen inventory browser started by "lgrt tui".
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/elsni/lagerator/data"
+	"github.com/elsni/lagerator/logic"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// help is shown in the status line.
+const help = "[yellow]Tab[-] pane  [yellow]/[-] search  [yellow]a[-] add items  [yellow]n[-] new  [yellow]e[-] edit  [yellow]m[-] move  " +
+	"[yellow]d[-] delete  [yellow]t/T[-] tag/untag  [yellow]+/-[-] put/take  [yellow]w[-] switch warehouse  [yellow]r[-] reload  [yellow]q[-] quit"
+
+// viewNode references a saved view in the tree, "" is the node holding them.
+type viewNode string
+
+// browser holds the panes of the running application.
+type browser struct {
+	app     *tview.Application
+	pages   *tview.Pages
+	tree    *tview.TreeView
+	search  *tview.InputField
+	table   *tview.Table
+	details *tview.TextView
+	status  *tview.TextView
+}
+
+// Run shows the browser until the user quits.
+func Run() error {
+	b := newBrowser()
+	b.refresh(data.Ref{})
+	b.setStatus(help)
+	return b.app.Run()
+}
+
+// newBrowser builds the panes and their layout.
+func newBrowser() *browser {
+	b := &browser{app: tview.NewApplication()}
+
+	b.tree = tview.NewTreeView()
+	b.tree.SetBorder(true).SetTitle(" Inventory ")
+	b.tree.SetChangedFunc(func(node *tview.TreeNode) { b.nodeChanged() })
+	b.tree.SetSelectedFunc(func(node *tview.TreeNode) { node.SetExpanded(!node.IsExpanded()) })
+
+	b.search = tview.NewInputField().SetLabel(" Search: ").SetFieldBackgroundColor(tcell.NewRGBColor(20, 20, 20))
+	b.search.SetChangedFunc(func(string) { b.fillTable() })
+	b.search.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			b.search.SetText("")
+		}
+		b.app.SetFocus(b.table)
+	})
+
+	b.table = tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	b.table.SetBorder(true)
+	b.table.SetSelectionChangedFunc(func(row, column int) { b.showDetails(b.selected()) })
+
+	b.details = tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	b.details.SetBorder(true).SetTitle(" Details ")
+
+	b.status = tview.NewTextView().SetDynamicColors(true)
+
+	right := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(b.search, 1, 0, false).
+		AddItem(b.table, 0, 1, false).
+		AddItem(b.details, 0, 1, false)
+	panes := tview.NewFlex().
+		AddItem(b.tree, 0, 1, true).
+		AddItem(right, 0, 2, false)
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(panes, 0, 1, true).
+		AddItem(b.status, 1, 0, false)
+	b.pages = tview.NewPages().AddPage("main", root, true, true)
+	b.app.SetRoot(b.pages, true).EnableMouse(true)
+	b.app.SetInputCapture(b.keys)
+	return b
+}
+
+// refresh rebuilds the tree from the database and selects the node with the
+// given reference, a data.Ref or a viewNode.
+func (b *browser) refresh(selected any) {
+	root := tview.NewTreeNode("Inventory").SetReference(data.Ref{}).SetColor(tcell.ColorYellow)
+	for _, wh := range sortedEntries(data.Db.Warehouses) {
+		name := wh.Name
+		if wh.Id == data.Db.CurrentWarehouse {
+			name += "*"
+		}
+		whnode := addNode(root, name, data.Ref{Table: "warehouses", ID: wh.Id})
+		for _, room := range data.GetRoomNamesforWarehouse(&data.Db.Rooms, wh.Id) {
+			roomnode := addNode(whnode, room.Name, data.Ref{Table: "rooms", ID: room.Id})
+			for _, shelf := range data.GetShelfNamesforRoom(&data.Db.Shelves, room.Id) {
+				shelfnode := addNode(roomnode, shelf.Name, data.Ref{Table: "shelves", ID: shelf.Id})
+				for _, box := range data.GetBoxNamesforShelf(&data.Db.Boxes, shelf.Id) {
+					addNode(shelfnode, box.Name, data.Ref{Table: "boxes", ID: box.Id})
+				}
+			}
+		}
+	}
+	if names := data.Db.ViewNames(); len(names) > 0 {
+		views := tview.NewTreeNode("Views").SetReference(viewNode("")).SetColor(tcell.ColorGreen)
+		root.AddChild(views)
+		for _, name := range names {
+			views.AddChild(tview.NewTreeNode(tview.Escape(name)).SetReference(viewNode(name)).SetColor(tcell.ColorGreen))
+		}
+	}
+	b.tree.SetRoot(root)
+	current := root
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		if node.GetReference() == selected {
+			current = node
+		}
+		return true
+	})
+	b.tree.SetCurrentNode(current)
+	b.nodeChanged()
+}
+
+// addNode adds a tree node for a record.
+func addNode(parent *tview.TreeNode, name string, ref data.Ref) *tview.TreeNode {
+	node := tview.NewTreeNode(tview.Escape(name)).SetReference(ref).SetSelectable(true)
+	parent.AddChild(node)
+	return node
+}
+
+// sortedEntries returns the live sets of a table sorted by name.
+func sortedEntries[T data.CustomData](tbl data.DataTable[T]) []data.Listentry {
+	var list []data.Listentry
+	for _, set := range tbl {
+		if !set.Deleted {
+			list = append(list, data.Listentry{Id: set.ID, Name: set.Name})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return strings.ToUpper(list[i].Name) < strings.ToUpper(list[j].Name) })
+	return list
+}
+
+// node returns the record of the current tree node, the zero Ref for the
+// root and the views.
+func (b *browser) node() data.Ref {
+	if current := b.tree.GetCurrentNode(); current != nil {
+		ref, _ := current.GetReference().(data.Ref)
+		return ref
+	}
+	return data.Ref{}
+}
+
+// view returns the saved view of the current tree node.
+func (b *browser) view() (viewNode, bool) {
+	if current := b.tree.GetCurrentNode(); current != nil {
+		view, ok := current.GetReference().(viewNode)
+		return view, ok
+	}
+	return "", false
+}
+
+// nodeChanged shows the items and details of the current tree node.
+func (b *browser) nodeChanged() {
+	b.fillTable()
+	if view, ok := b.view(); ok {
+		b.showView(view)
+		return
+	}
+	b.showDetails(b.node())
+}
+
+// showView shows the query of a saved view.
+func (b *browser) showView(view viewNode) {
+	b.details.Clear()
+	if view == "" {
+		fmt.Fprint(b.details, "Saved views, add one with lgrt view save <name> <query>.")
+		return
+	}
+	fmt.Fprintf(b.details, "[yellow]View[-]  %s\n[yellow]Query[-] %s", tview.Escape(string(view)), tview.Escape(data.Db.Views[string(view)]))
+}
+
+// fillTable lists the items in the current tree node that match the search.
+func (b *browser) fillTable() {
+	node := b.node()
+	query := strings.ToLower(strings.TrimSpace(b.search.GetText()))
+	contains := func(set data.Dataset[data.Item]) bool {
+		return node.Table == "" || slices.Contains(data.ItemPath(set.Data), node)
+	}
+	title := "all items"
+	if info, ok := data.Db.Record(node); ok {
+		title = "items in " + info.Name
+	}
+	if view, ok := b.view(); ok && view != "" {
+		title = "items in view " + string(view)
+		filter, err := data.Db.ViewFilter(string(view))
+		if err != nil {
+			b.setStatus(tview.Escape(fmt.Sprintf("Error: %v", err)))
+			filter = func(data.Dataset[data.Item]) bool { return false }
+		}
+		contains = filter
+	}
+	var items []data.Dataset[data.Item]
+	for _, set := range data.Db.Items {
+		if set.Deleted || !contains(set) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(set.Name), query) && !strings.Contains(strings.ToLower(set.Description), query) {
+			continue
+		}
+		items = append(items, set)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return strings.ToUpper(items[i].Name) < strings.ToUpper(items[j].Name) })
+
+	b.table.Clear()
+	for col, title := range []string{"ID", "Name", "Amount", "Category", "Box", "Lent to"} {
+		b.table.SetCell(0, col, tview.NewTableCell(title).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+	}
+	for i, set := range items {
+		borrower := ""
+		color := tcell.ColorWhite
+		if set.Data.Loan != nil {
+			borrower = set.Data.Loan.Borrower
+			if set.Data.Loan.Overdue() {
+				color = tcell.ColorRed
+			}
+		}
+		ref := data.Ref{Table: "items", ID: set.ID}
+		for col, text := range []string{
+			fmt.Sprint(set.ID),
+			set.Name,
+			fmt.Sprint(set.Data.Amount),
+			data.GetPrintNameById(&data.Db.Categories, set.Data.CategoryId, 20),
+			data.GetPrintNameById(&data.Db.Boxes, set.Data.BoxId, 20),
+			borrower,
+		} {
+			b.table.SetCell(i+1, col, tview.NewTableCell(tview.Escape(text)).SetTextColor(color).SetReference(ref).SetExpansion(1))
+		}
+	}
+	b.table.SetTitle(tview.Escape(fmt.Sprintf(" %d %s ", len(items), title)))
+	b.table.ScrollToBeginning()
+	b.table.Select(1, 0)
+}
+
+// selected returns the record the user works on: the item selected in the
+// table while it has the focus, otherwise the current tree node.
+func (b *browser) selected() data.Ref {
+	if b.app.GetFocus() == b.table {
+		row, _ := b.table.GetSelection()
+		if cell := b.table.GetCell(row, 0); row > 0 && cell.GetReference() != nil {
+			return cell.GetReference().(data.Ref)
+		}
+	}
+	return b.node()
+}
+
+// showDetails prints the details of a record with its Show method.
+func (b *browser) showDetails(ref data.Ref) {
+	b.details.Clear()
+	if ref.Table == "" {
+		fmt.Fprint(b.details, "Select an object in the tree or the item table.")
+		return
+	}
+	out := capture(func() { logic.ShowAny(ref.ID) })
+	fmt.Fprint(b.details, tview.TranslateANSI(out))
+	b.details.ScrollToBeginning()
+}
+
+// setStatus shows a message in the status line.
+func (b *browser) setStatus(text string) {
+	b.status.SetText(text)
+}
+
+// run calls a logic function outside of the full-screen mode, so it can open
+// its own forms, then shows its last message and the changed database.
+func (b *browser) run(fn func()) {
+	selected := b.selected()
+	inTable := b.app.GetFocus() == b.table
+	var out string
+	b.app.Suspend(func() { out = capture(fn) })
+	b.refresh(b.tree.GetCurrentNode().GetReference())
+	if inTable {
+		b.app.SetFocus(b.table)
+		b.selectItem(selected)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	b.setStatus(tview.TranslateANSI(lines[len(lines)-1]))
+}
+
+// selectItem selects the table row of an item.
+func (b *browser) selectItem(ref data.Ref) {
+	for row := 1; row < b.table.GetRowCount(); row++ {
+		if r, ok := b.table.GetCell(row, 0).GetReference().(data.Ref); ok && r == ref {
+			b.table.Select(row, 0)
+			return
+		}
+	}
+}
+
+// prompt asks for a line of text and calls done with it unless the user cancels.
+func (b *browser) prompt(label string, done func(text string)) {
+	previous := b.app.GetFocus()
+	input := tview.NewInputField().SetLabel(label).SetFieldBackgroundColor(tcell.NewRGBColor(20, 20, 20))
+	input.SetBorder(true)
+	input.SetDoneFunc(func(key tcell.Key) {
+		text := strings.TrimSpace(input.GetText())
+		b.pages.RemovePage("prompt")
+		b.app.SetFocus(previous)
+		if key == tcell.KeyEnter && text != "" {
+			done(text)
+		}
+	})
+	dialog := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(input, 3, 0, true).
+			AddItem(nil, 0, 1, false), 60, 0, true).
+		AddItem(nil, 0, 1, false)
+	b.pages.AddPage("prompt", dialog, true, true)
+	b.app.SetFocus(input)
+}
+
+// keys handles the key bindings of the main page.
+func (b *browser) keys(event *tcell.EventKey) *tcell.EventKey {
+	if front, _ := b.pages.GetFrontPage(); front != "main" || b.app.GetFocus() == b.search {
+		return event
+	}
+	if event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab {
+		if b.app.GetFocus() == b.tree {
+			b.app.SetFocus(b.table)
+			b.showDetails(b.selected())
+		} else if view, ok := b.view(); ok {
+			b.app.SetFocus(b.tree)
+			b.showView(view)
+		} else {
+			b.app.SetFocus(b.tree)
+			b.showDetails(b.node())
+		}
+		return nil
+	}
+	ref := b.selected()
+	switch event.Rune() {
+	case 'q':
+		b.app.Stop()
+	case '/':
+		b.app.SetFocus(b.search)
+	case '?':
+		b.setStatus(help)
+	case 'r':
+		if err := data.Db.Load(); err != nil {
+			b.setStatus(tview.Escape(fmt.Sprintf("Error: %v", err)))
+			return nil
+		}
+		b.refresh(b.tree.GetCurrentNode().GetReference())
+		b.setStatus("Reloaded the database")
+	case 'a':
+		b.addItems()
+	case 'n':
+		b.newChild()
+	case 'e':
+		if ref.Table != "" {
+			b.run(func() { logic.EditAny(ref.ID) })
+		}
+	case 'd':
+		if ref.Table != "" {
+			b.run(func() { logic.DeleteAny(ref.ID, logic.DeleteOptions{}) })
+		}
+	case 'm':
+		switch ref.Table {
+		case "items":
+			b.prompt("Move to box (name or id): ", func(box string) { b.run(func() { logic.MoveItem(ref.ID, box) }) })
+		case "boxes":
+			b.prompt("Move to shelf (name or id): ", func(shelf string) { b.run(func() { logic.MoveBox(ref.ID, shelf) }) })
+		default:
+			b.setStatus("Only items and boxes can be moved")
+		}
+	case 't':
+		if ref.Table != "" {
+			b.prompt("Add tag: ", func(tag string) { b.run(func() { logic.AddTag(tag, ref.ID) }) })
+		}
+	case 'T':
+		if ref.Table != "" {
+			b.prompt("Remove tag: ", func(tag string) { b.run(func() { logic.RemoveTag(tag, ref.ID) }) })
+		}
+	case '+', '-':
+		if ref.Table != "items" {
+			b.setStatus("Select an item in the table")
+			return nil
+		}
+		if event.Rune() == '+' {
+			b.run(func() { logic.Put(ref.ID, 1, "") })
+		} else {
+			b.run(func() { logic.Take(ref.ID, 1, "") })
+		}
+	case 'w':
+		if ref.Table != "warehouses" {
+			b.setStatus("Select a warehouse in the tree")
+			return nil
+		}
+		if info, ok := data.Db.Record(ref); ok {
+			b.run(func() { logic.SwitchWarehouse(info.Name) })
+		}
+	default:
+		return event
+	}
+	return nil
+}
+
+// addItems opens the add form for the selected box or the box of the selected item.
+func (b *browser) addItems() {
+	ref := b.selected()
+	if ref.Table == "items" {
+		if item, ok := data.Db.Items.GetPtr(ref.ID); ok {
+			ref = data.Ref{Table: "boxes", ID: item.Data.BoxId}
+		}
+	}
+	if ref.Table != "boxes" {
+		b.setStatus("Select a box to add items to")
+		return
+	}
+	b.run(func() { logic.AddItems(fmt.Sprint(ref.ID)) })
+}
+
+// newChild adds a warehouse, room, shelf or box below the current tree node.
+func (b *browser) newChild() {
+	if _, ok := b.view(); ok {
+		b.setStatus("Views are saved with lgrt view save <name> <query>")
+		return
+	}
+	node := b.node()
+	id := fmt.Sprint(node.ID)
+	switch node.Table {
+	case "":
+		b.prompt("New warehouse: ", func(name string) { b.run(func() { logic.AddWarehouse(name) }) })
+	case "warehouses":
+		if node.ID != data.Db.CurrentWarehouse {
+			b.setStatus("Rooms are added to the current warehouse, switch to it with w first")
+			return
+		}
+		b.prompt("New room: ", func(name string) { b.run(func() { logic.AddRoomToCurrentWarehouse(name) }) })
+	case "rooms":
+		b.prompt("New shelf: ", func(name string) { b.run(func() { logic.AddShelfToRoom(name, id) }) })
+	case "shelves":
+		b.prompt("New box: ", func(name string) { b.run(func() { logic.AddBoxToShelf(name, id) }) })
+	case "boxes":
+		b.addItems()
+	}
+}
+
+// capture returns what fn prints to stdout.
+func capture(fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		fn()
+		return ""
+	}
+	old := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		r.Close()
+		done <- string(out)
+	}()
+	fn()
+	os.Stdout = old
+	w.Close()
+	return <-done
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/elsni/lagerator/data"
@@ -77,6 +78,8 @@ func EditItem[T data.CustomData](r data.Dataset[T], idoptions [][]DropdownOption
 	ididx := 0
 	fheight := 17
 	taglist := ""
+	// formidx maps the edited fields to their form items, fields of other types are not shown
+	formidx := map[string]int{}
 
 	form := tview.NewForm().
 		AddTextView("Id", fmt.Sprint(r.ID), 5, 1, false, false).
@@ -87,6 +90,10 @@ func EditItem[T data.CustomData](r data.Dataset[T], idoptions [][]DropdownOption
 		fieldtype, _ := reflections.GetFieldType(r.Data, fieldName)
 		fieldvalue, _ := reflections.GetField(r.Data, fieldName)
 		switch fieldtype {
+		case "string", "uint32", "int", "data.Date", "data.Money":
+			formidx[fieldName] = form.GetFormItemCount()
+		}
+		switch fieldtype {
 		case "string":
 			form.AddInputField(fieldName, fieldvalue.(string), 40, nil, nil)
 			fheight += 2
@@ -149,6 +156,16 @@ func EditItem[T data.CustomData](r data.Dataset[T], idoptions [][]DropdownOption
 				return err == nil
 			}, nil)
 			fheight += 2
+		case "data.Date":
+			form.AddInputField(fieldName, fieldvalue.(data.Date).String(), 11, func(text string, last rune) bool {
+				return strings.ContainsRune("0123456789-.", last)
+			}, nil)
+			fheight += 2
+		case "data.Money":
+			form.AddInputField(fieldName, fieldvalue.(data.Money).String(), 12, func(text string, last rune) bool {
+				return strings.ContainsRune("0123456789.,", last)
+			}, nil)
+			fheight += 2
 		default:
 			loggi.Log.Log(fieldtype)
 		}
@@ -157,12 +174,22 @@ func EditItem[T data.CustomData](r data.Dataset[T], idoptions [][]DropdownOption
 	tagfield := tview.NewInputField().SetLabel("Tags").SetText(data.GetTagList(r.Tags)).SetFieldWidth(40).SetChangedFunc(func(text string) { taglist = text })
 	form.AddFormItem(tagfield)
 	form.AddButton("Ok", func() {
+		// check dates and prices first, the form stays open on invalid input
+		for _, fieldName := range fields {
+			fieldtype, _ := reflections.GetFieldType(r.Data, fieldName)
+			if i, ok := formidx[fieldName]; ok {
+				if _, err := parseField(fieldtype, form.GetFormItem(i)); err != nil {
+					form.SetTitle(fmt.Sprintf(" %s: %v ", fieldName, err))
+					form.SetFocus(i)
+					return
+				}
+			}
+		}
 		ididx = 0
-		for i, fieldName := range fields {
+		for _, fieldName := range fields {
 			fieldtype, _ := reflections.GetFieldType(r.Data, fieldName)
-			f := form.GetFormItem(i + 3)
-			if f != nil {
-				fmt.Println(fieldtype)
+			if i, ok := formidx[fieldName]; ok {
+				f := form.GetFormItem(i)
 				switch fieldtype {
 				case "string":
 					text := f.(*tview.InputField).GetText()
@@ -178,6 +205,9 @@ func EditItem[T data.CustomData](r data.Dataset[T], idoptions [][]DropdownOption
 					text := f.(*tview.InputField).GetText()
 					val, _ := strconv.Atoi(text)
 					reflections.SetField(&r.Data, fieldName, val)
+				case "data.Date", "data.Money":
+					value, _ := parseField(fieldtype, f)
+					reflections.SetField(&r.Data, fieldName, value)
 				default:
 				}
 			}
@@ -242,6 +272,18 @@ func EditItem[T data.CustomData](r data.Dataset[T], idoptions [][]DropdownOption
 	return r, saved
 }
 
+// parseField parses the text of a date or price input field.
+// Fields of other types are not checked and return nil.
+func parseField(fieldtype string, f tview.FormItem) (any, error) {
+	switch fieldtype {
+	case "data.Date":
+		return data.ParseDate(f.(*tview.InputField).GetText())
+	case "data.Money":
+		return data.ParseMoney(f.(*tview.InputField).GetText())
+	}
+	return nil, nil
+}
+
 // Alert shows a confirmation dialog and returns true on acceptance.
 func Alert(message string) bool {
 	app := tview.NewApplication()
@@ -270,6 +312,46 @@ func Alert(message string) bool {
 	return result
 }
 
+// Choose shows a message with one button per option and returns the
+// index of the chosen option, or -1 if the dialog was left with Escape.
+func Choose(message string, options []string) int {
+	app := tview.NewApplication()
+	result := -1
+	form, width := chooseForm(message, options, func(i int) {
+		result = i
+		app.Stop()
+	})
+	form.SetCancelFunc(func() {
+		app.Stop()
+	})
+
+	form.SetBorder(true).SetTitle("Please choose").SetTitleAlign(tview.AlignLeft)
+	form.SetFieldBackgroundColor(tcell.NewRGBColor(20, 20, 20))
+	form.SetFieldTextColor(tcell.ColorWhite)
+	form.SetButtonTextColor(tcell.ColorRed)
+	form.SetRect(0, 0, max(42, width+4), 10)
+	if err := app.SetRoot(form, false).Run(); err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// chooseForm builds the form of Choose, chosen is called with the index of
+// the pressed button. It also returns the width the buttons need.
+func chooseForm(message string, options []string, chosen func(int)) (*tview.Form, int) {
+	form := tview.NewForm()
+	form.AddTextView("", message, 40, 3, true, false)
+	width := 0
+	for i, option := range options {
+		i := i
+		form.AddButton(option, func() {
+			chosen(i)
+		})
+		width += len(option) + 6
+	}
+	return form, width
+}
+
 // SelectItem lets the user choose an item and returns its index or -1.
 func SelectItem[T data.CustomData](items []data.Dataset[T], action string) int {
 	numitems := len(items)
@@ -0,0 +1,21 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// TestChooseForm verifies that every button reports its own option.
+func TestChooseForm(t *testing.T) {
+	options := []string{"Delete all", "Move contents", "Cancel"}
+	chosen := -1
+	form, _ := chooseForm("Box \"B1\" contains 2 items.", options, func(i int) { chosen = i })
+	for i := range options {
+		form.GetButton(i).InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+		if chosen != i {
+			t.Fatalf("expected option %d, got %d", i, chosen)
+		}
+	}
+}